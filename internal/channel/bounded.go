@@ -0,0 +1,162 @@
+package channel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dsx137/gg-kit/internal/lang"
+	"github.com/dsx137/gg-kit/internal/structure"
+)
+
+// BoundedChannel is a channel with a fixed capacity whose OverflowPolicy decides what Send does when it is full.
+type BoundedChannel[T any] struct {
+	mu        *sync.Mutex
+	items     *structure.Queue[T]
+	capacity  int
+	policy    OverflowPolicy
+	notFull   chan struct{}
+	closed    bool
+	dropped   uint64
+	highWater int
+	recv      *receiver[T]
+}
+
+func NewBoundedChannel[T any](capacity int, policy OverflowPolicy) *BoundedChannel[T] {
+	if capacity < 1 {
+		panic("capacity must be positive")
+	}
+	c := &BoundedChannel[T]{
+		mu:       &sync.Mutex{},
+		items:    structure.NewQueue[T](),
+		capacity: capacity,
+		policy:   policy,
+	}
+	c.recv = newReceiver[T](c.mu, c)
+	return c
+}
+
+func (c *BoundedChannel[T]) Send(v T) error {
+	return c.SendCtx(context.Background(), v)
+}
+
+// SendCtx returns ErrClosed after Close and ErrFull under OverflowError. Under OverflowBlock it waits for room
+// and returns ctx.Err() if ctx is done first; the drop policies never block.
+func (c *BoundedChannel[T]) SendCtx(ctx context.Context, v T) error {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return ErrClosed
+		}
+		if c.items.Len() < c.capacity {
+			c.pushLocked(v)
+			c.mu.Unlock()
+			return nil
+		}
+
+		switch c.policy {
+		case OverflowDropNewest:
+			c.dropped++
+			c.mu.Unlock()
+			return nil
+		case OverflowDropOldest:
+			// The oldest item may be on offer to an Out reader, so take it the way a receive would and
+			// retry, since others may have sent or received meanwhile.
+			if _, ok := c.recv.takeLocked(); ok {
+				c.dropped++
+			}
+			c.mu.Unlock()
+			continue
+		case OverflowError:
+			c.dropped++
+			c.mu.Unlock()
+			return ErrFull
+		}
+
+		if c.notFull == nil {
+			c.notFull = make(chan struct{})
+		}
+		notFull := c.notFull
+		c.mu.Unlock()
+
+		select {
+		case <-notFull:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *BoundedChannel[T]) TryRecv() (T, bool) {
+	return c.recv.tryRecv()
+}
+
+func (c *BoundedChannel[T]) Recv() (T, bool) {
+	v, err := c.RecvCtx(context.Background())
+	return v, err == nil
+}
+
+// RecvCtx returns ErrClosed once the channel is closed and drained, or ctx.Err() if ctx is done first.
+func (c *BoundedChannel[T]) RecvCtx(ctx context.Context) (T, error) {
+	return c.recv.recvCtx(ctx)
+}
+
+// Out returns a channel fed from c, closed once c is closed and drained. An item stays in c, counted by Len
+// and against the capacity, until an Out reader has taken it.
+func (c *BoundedChannel[T]) Out() <-chan T {
+	return c.recv.pump()
+}
+
+// Close is idempotent; buffered items remain receivable and blocked senders get ErrClosed.
+func (c *BoundedChannel[T]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.recv.notifyLocked()
+	c.notFull = lang.Wake(c.notFull)
+}
+
+func (c *BoundedChannel[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items.Len()
+}
+
+func (c *BoundedChannel[T]) Cap() int {
+	return c.capacity
+}
+
+// Dropped returns how many items were discarded or rejected by the overflow policy.
+func (c *BoundedChannel[T]) Dropped() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dropped
+}
+
+// HighWater returns the largest number of items the channel has held at once.
+func (c *BoundedChannel[T]) HighWater() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.highWater
+}
+
+func (c *BoundedChannel[T]) pushLocked(v T) {
+	c.items.Enqueue(v)
+	c.highWater = max(c.highWater, c.items.Len())
+	c.recv.notifyLocked()
+}
+
+func (c *BoundedChannel[T]) headLocked() (T, func(), bool) {
+	v, ok := c.items.Peek()
+	return v, func() {
+		c.items.Dequeue()
+		c.notFull = lang.Wake(c.notFull)
+	}, ok
+}
+
+func (c *BoundedChannel[T]) closedLocked() bool {
+	return c.closed
+}
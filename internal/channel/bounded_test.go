@@ -0,0 +1,89 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func TestBoundedChannelPolicies(t *testing.T) {
+	cases := []struct {
+		policy channel.OverflowPolicy
+		want   []int
+		err    error
+	}{
+		{channel.OverflowDropNewest, []int{0, 1, 2}, nil},
+		{channel.OverflowDropOldest, []int{2, 3, 4}, nil},
+		{channel.OverflowError, []int{0, 1, 2}, channel.ErrFull},
+	}
+	for _, c := range cases {
+		ch := channel.NewBoundedChannel[int](3, c.policy)
+		var lastErr error
+		for i := 0; i < 5; i++ {
+			if err := ch.Send(i); err != nil {
+				lastErr = err
+			}
+		}
+		if !errors.Is(lastErr, c.err) {
+			t.Fatalf("policy %d: expected error %v, got %v", c.policy, c.err, lastErr)
+		}
+		if ch.Dropped() != 2 || ch.HighWater() != 3 {
+			t.Fatalf("policy %d: expected 2 dropped and high water 3, got %d and %d", c.policy, ch.Dropped(), ch.HighWater())
+		}
+		ch.Close()
+		for _, want := range c.want {
+			if v, ok := ch.Recv(); !ok || v != want {
+				t.Fatalf("policy %d: expected %d, got %d (ok=%v)", c.policy, want, v, ok)
+			}
+		}
+		if err := ch.Send(0); !errors.Is(err, channel.ErrClosed) {
+			t.Fatalf("expected ErrClosed after close, got %v", err)
+		}
+	}
+}
+
+func TestBoundedChannelBlock(t *testing.T) {
+	ch := channel.NewBoundedChannel[int](1, channel.OverflowBlock)
+	_ = ch.Send(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := ch.SendCtx(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	sent := make(chan error)
+	go func() { sent <- ch.Send(3) }()
+	if v, _ := ch.Recv(); v != 1 {
+		t.Fatalf("expected 1, got %d", v)
+	}
+	if err := <-sent; err != nil {
+		t.Fatalf("expected blocked send to succeed, got %v", err)
+	}
+	if v, _ := ch.Recv(); v != 3 {
+		t.Fatalf("expected 3, got %d", v)
+	}
+}
+
+func TestBoundedChannelDropOldestWithOut(t *testing.T) {
+	c := channel.NewBoundedChannel[int](2, channel.OverflowDropOldest)
+	out := c.Out()
+	c.Send(0)
+	c.Send(1)
+	// Let the pump offer 0 before it is dropped.
+	time.Sleep(10 * time.Millisecond)
+	c.Send(2)
+
+	if v := <-out; v != 1 {
+		t.Fatalf("expected the offered oldest item to be dropped, got %d", v)
+	}
+	if v := <-out; v != 2 {
+		t.Fatalf("expected 2, got %d", v)
+	}
+	if d := c.Dropped(); d != 1 {
+		t.Fatalf("expected 1 dropped, got %d", d)
+	}
+}
@@ -0,0 +1,93 @@
+package channel
+
+import (
+	"context"
+	"sync"
+)
+
+// Broadcaster delivers every item of its input to all current subscribers.
+// Subscriber channels are closed on Unsubscribe, or once the input is closed or ctx is done.
+type Broadcaster[T any] struct {
+	mu      *sync.Mutex
+	subs    map[<-chan T]*subscriber[T]
+	stopped bool
+}
+
+func NewBroadcaster[T any](ctx context.Context, in <-chan T) *Broadcaster[T] {
+	b := &Broadcaster[T]{
+		mu:   &sync.Mutex{},
+		subs: make(map[<-chan T]*subscriber[T]),
+	}
+	go b.run(ctx, in)
+	return b
+}
+
+func (b *Broadcaster[T]) run(ctx context.Context, in <-chan T) {
+	defer b.stop()
+	ConsumeWithCtx(ctx, in, func(v T) bool {
+		for _, s := range b.snapshot() {
+			if s.send(ctx, v) != nil {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (b *Broadcaster[T]) snapshot() []*subscriber[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := make([]*subscriber[T], 0, len(b.subs))
+	for _, s := range b.subs {
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+func (b *Broadcaster[T]) stop() {
+	b.mu.Lock()
+	subs := b.subs
+	b.subs = nil
+	b.stopped = true
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		s.close()
+	}
+}
+
+// Subscribe returns a channel with the given buffer size. After the broadcaster has stopped it returns a closed channel.
+func (b *Broadcaster[T]) Subscribe(buffer int) <-chan T {
+	s := newSubscriber[T](buffer, OverflowBlock)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		close(s.ch)
+		return s.ch
+	}
+	b.subs[s.ch] = s
+	return s.ch
+}
+
+// Unsubscribe closes ch and reports whether it was subscribed. A delivery blocked on ch is abandoned.
+func (b *Broadcaster[T]) Unsubscribe(ch <-chan T) bool {
+	b.mu.Lock()
+	s, ok := b.subs[ch]
+	if ok {
+		delete(b.subs, ch)
+	}
+	b.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	s.close()
+	return true
+}
+
+func (b *Broadcaster[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
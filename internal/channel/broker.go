@@ -0,0 +1,223 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	TopicSeparator = "."
+	// WildcardOne matches exactly one topic level.
+	WildcardOne = "*"
+	// WildcardMany matches zero or more topic levels.
+	WildcardMany = "#"
+)
+
+type topicNode[T any] struct {
+	children map[string]*topicNode[T]
+	subs     map[*Subscription[T]]struct{}
+}
+
+func newTopicNode[T any]() *topicNode[T] {
+	return &topicNode[T]{
+		children: make(map[string]*topicNode[T]),
+		subs:     make(map[*Subscription[T]]struct{}),
+	}
+}
+
+func (n *topicNode[T]) match(levels []string, found map[*Subscription[T]]struct{}) {
+	if many, ok := n.children[WildcardMany]; ok {
+		for i := 0; i <= len(levels); i++ {
+			many.match(levels[i:], found)
+		}
+	}
+	if len(levels) == 0 {
+		for s := range n.subs {
+			found[s] = struct{}{}
+		}
+		return
+	}
+	if child, ok := n.children[levels[0]]; ok {
+		child.match(levels[1:], found)
+	}
+	if one, ok := n.children[WildcardOne]; ok {
+		one.match(levels[1:], found)
+	}
+}
+
+// Subscription receives the messages published to topics matching its pattern.
+type Subscription[T any] struct {
+	*subscriber[T]
+	broker  *Broker[T]
+	pattern string
+}
+
+func (s *Subscription[T]) Out() <-chan T {
+	return s.ch
+}
+
+func (s *Subscription[T]) Pattern() string {
+	return s.pattern
+}
+
+// Dropped returns how many messages were discarded by the overflow policy.
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe closes Out and reports whether s was still subscribed. It is safe to call during delivery.
+func (s *Subscription[T]) Unsubscribe() bool {
+	return s.broker.unsubscribe(s)
+}
+
+// Broker is an in-process pub/sub hub over dot-separated topics such as "orders.eu.created".
+// Patterns may use "*" for one level and "#" for any number of levels.
+type Broker[T any] struct {
+	mu     *sync.RWMutex
+	root   *topicNode[T]
+	closed bool
+}
+
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{
+		mu:   &sync.RWMutex{},
+		root: newTopicNode[T](),
+	}
+}
+
+func (b *Broker[T]) Subscribe(pattern string, buffer int, policy OverflowPolicy) (*Subscription[T], error) {
+	levels, err := splitTopic(pattern, true)
+	if err != nil {
+		return nil, err
+	}
+	s := &Subscription[T]{
+		subscriber: newSubscriber[T](buffer, policy),
+		broker:     b,
+		pattern:    pattern,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	n := b.root
+	for _, level := range levels {
+		child, ok := n.children[level]
+		if !ok {
+			child = newTopicNode[T]()
+			n.children[level] = child
+		}
+		n = child
+	}
+	n.subs[s] = struct{}{}
+	return s, nil
+}
+
+// Publish delivers v to every matching subscription. It returns ctx.Err() if ctx is done while
+// a subscription with OverflowBlock is full, in which case the remaining subscriptions are skipped,
+// and ErrFull if a subscription with OverflowError rejected v.
+func (b *Broker[T]) Publish(ctx context.Context, topic string, v T) error {
+	levels, err := splitTopic(topic, false)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[*Subscription[T]]struct{})
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+	b.root.match(levels, found)
+	b.mu.RUnlock()
+
+	var full error
+	for s := range found {
+		if err := s.send(ctx, v); errors.Is(err, ErrFull) {
+			full = err
+		} else if err != nil {
+			return err
+		}
+	}
+	return full
+}
+
+// Close unsubscribes everyone; later calls to Subscribe and Publish return ErrClosed.
+func (b *Broker[T]) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	var subs []*Subscription[T]
+	var collect func(n *topicNode[T])
+	collect = func(n *topicNode[T]) {
+		for s := range n.subs {
+			subs = append(subs, s)
+		}
+		for _, child := range n.children {
+			collect(child)
+		}
+	}
+	collect(b.root)
+	b.root = newTopicNode[T]()
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		s.close()
+	}
+}
+
+func (b *Broker[T]) unsubscribe(s *Subscription[T]) bool {
+	levels, _ := splitTopic(s.pattern, true)
+
+	b.mu.Lock()
+	path := []*topicNode[T]{b.root}
+	for _, level := range levels {
+		child, ok := path[len(path)-1].children[level]
+		if !ok {
+			b.mu.Unlock()
+			return false
+		}
+		path = append(path, child)
+	}
+	if _, ok := path[len(path)-1].subs[s]; !ok {
+		b.mu.Unlock()
+		return false
+	}
+	delete(path[len(path)-1].subs, s)
+	for i := len(path) - 1; i > 0 && len(path[i].subs) == 0 && len(path[i].children) == 0; i-- {
+		delete(path[i-1].children, levels[i-1])
+	}
+	b.mu.Unlock()
+
+	s.close()
+	return true
+}
+
+func splitTopic(topic string, wildcards bool) ([]string, error) {
+	if topic == "" {
+		return nil, fmt.Errorf("empty topic")
+	}
+	levels := strings.Split(topic, TopicSeparator)
+	for _, level := range levels {
+		if level == "" {
+			return nil, fmt.Errorf("empty level in topic %q", topic)
+		}
+		if level == WildcardOne || level == WildcardMany {
+			if !wildcards {
+				return nil, fmt.Errorf("wildcard in published topic %q", topic)
+			}
+			continue
+		}
+		if strings.ContainsAny(level, WildcardOne+WildcardMany) {
+			return nil, fmt.Errorf("wildcard must span a whole level in %q", topic)
+		}
+	}
+	return levels, nil
+}
@@ -0,0 +1,111 @@
+package channel_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func TestBrokerWildcards(t *testing.T) {
+	b := channel.NewBroker[string]()
+	defer b.Close()
+
+	patterns := map[string][]string{
+		"orders.eu.created": {"orders.eu.created"},
+		"orders.*.created":  {"orders.eu.created", "orders.us.created"},
+		"orders.#":          {"orders", "orders.eu.created", "orders.us.created", "orders.eu.paid"},
+		"#.paid":            {"orders.eu.paid"},
+		"*":                 {"orders"},
+	}
+	topics := []string{"orders", "orders.eu.created", "orders.us.created", "orders.eu.paid", "users.created"}
+
+	subs := make(map[string]*channel.Subscription[string])
+	for pattern := range patterns {
+		s, err := b.Subscribe(pattern, len(topics), channel.OverflowBlock)
+		if err != nil {
+			t.Fatalf("subscribe %q: %v", pattern, err)
+		}
+		subs[pattern] = s
+	}
+	for _, topic := range topics {
+		if err := b.Publish(context.Background(), topic, topic); err != nil {
+			t.Fatalf("publish %q: %v", topic, err)
+		}
+	}
+
+	for pattern, want := range patterns {
+		s := subs[pattern]
+		s.Unsubscribe()
+		var got []string
+		channel.Consume(s.Out(), func(v string) bool {
+			got = append(got, v)
+			return true
+		})
+		if len(got) != len(want) {
+			t.Fatalf("%q: expected %v, got %v", pattern, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%q: expected %v, got %v", pattern, want, got)
+			}
+		}
+	}
+}
+
+func TestBrokerInvalidTopics(t *testing.T) {
+	b := channel.NewBroker[int]()
+	for _, pattern := range []string{"", "a..b", "a.b*", "a.#x"} {
+		if _, err := b.Subscribe(pattern, 0, channel.OverflowBlock); err == nil {
+			t.Fatalf("expected error for pattern %q", pattern)
+		}
+	}
+	if err := b.Publish(context.Background(), "a.*", 1); err == nil {
+		t.Fatal("expected error publishing to a wildcard topic")
+	}
+}
+
+func TestBrokerOverflow(t *testing.T) {
+	b := channel.NewBroker[int]()
+	newest, _ := b.Subscribe("t", 2, channel.OverflowDropNewest)
+	oldest, _ := b.Subscribe("t", 2, channel.OverflowDropOldest)
+	for i := 0; i < 5; i++ {
+		_ = b.Publish(context.Background(), "t", i)
+	}
+	b.Close()
+
+	if newest.Dropped() != 3 || <-newest.Out() != 0 || <-newest.Out() != 1 {
+		t.Fatal("expected DropNewest to keep the first items")
+	}
+	if oldest.Dropped() != 3 || <-oldest.Out() != 3 || <-oldest.Out() != 4 {
+		t.Fatal("expected DropOldest to keep the last items")
+	}
+	if _, err := b.Subscribe("t", 0, channel.OverflowBlock); err != channel.ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestBrokerUnsubscribeDuringDelivery(t *testing.T) {
+	b := channel.NewBroker[int]()
+	defer b.Close()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		s, _ := b.Subscribe("t", 0, channel.OverflowBlock)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			channel.ConsumeWithCtx(context.Background(), s.Out(), func(int) bool {
+				s.Unsubscribe()
+				return true
+			})
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		if err := b.Publish(context.Background(), "t", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
@@ -1,12 +1,90 @@
 package channel
 
 import (
-	"container/list"
+	"context"
+	"errors"
 	"sync"
+
+	"github.com/dsx137/gg-kit/internal/structure"
 )
 
-// ggkit:ignore
-type Channel struct {
-	mu    *sync.Mutex
-	items *list.List
+var ErrClosed = errors.New("channel closed")
+
+// Channel is an unbounded channel: Send never blocks, receivers block while it is empty.
+type Channel[T any] struct {
+	mu     *sync.Mutex
+	items  *structure.Queue[T]
+	closed bool
+	recv   *receiver[T]
+}
+
+func NewChannel[T any]() *Channel[T] {
+	c := &Channel[T]{
+		mu:    &sync.Mutex{},
+		items: structure.NewQueue[T](),
+	}
+	c.recv = newReceiver[T](c.mu, c)
+	return c
+}
+
+func (c *Channel[T]) Send(v T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		panic("send on closed channel")
+	}
+	c.items.Enqueue(v)
+	c.recv.notifyLocked()
+}
+
+func (c *Channel[T]) TryRecv() (T, bool) {
+	return c.recv.tryRecv()
+}
+
+func (c *Channel[T]) Recv() (T, bool) {
+	v, err := c.RecvCtx(context.Background())
+	return v, err == nil
+}
+
+// RecvCtx returns ErrClosed once the channel is closed and drained, or ctx.Err() if ctx is done first.
+func (c *Channel[T]) RecvCtx(ctx context.Context) (T, error) {
+	return c.recv.recvCtx(ctx)
+}
+
+// Out returns a channel fed from c, closed once c is closed and drained. An item stays in c, counted by Len
+// and available to every other receive, until an Out reader has taken it.
+func (c *Channel[T]) Out() <-chan T {
+	return c.recv.pump()
+}
+
+// Close is idempotent; buffered items remain receivable.
+func (c *Channel[T]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.recv.notifyLocked()
+}
+
+func (c *Channel[T]) Closed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+func (c *Channel[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items.Len()
+}
+
+func (c *Channel[T]) headLocked() (T, func(), bool) {
+	v, ok := c.items.Peek()
+	return v, func() { c.items.Dequeue() }, ok
+}
+
+func (c *Channel[T]) closedLocked() bool {
+	return c.closed
 }
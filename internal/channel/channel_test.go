@@ -0,0 +1,164 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func TestChannelSendRecv(t *testing.T) {
+	c := channel.NewChannel[int]()
+	for i := 0; i < 1000; i++ {
+		c.Send(i)
+	}
+	if c.Len() != 1000 {
+		t.Fatalf("expected len 1000, got %d", c.Len())
+	}
+	for i := 0; i < 1000; i++ {
+		v, ok := c.Recv()
+		if !ok || v != i {
+			t.Fatalf("expected %d, got %d (ok=%v)", i, v, ok)
+		}
+	}
+	if _, ok := c.TryRecv(); ok {
+		t.Fatal("expected empty channel")
+	}
+}
+
+func TestChannelCloseDrains(t *testing.T) {
+	c := channel.NewChannel[int]()
+	c.Send(1)
+	c.Close()
+	c.Close()
+
+	if v, ok := c.Recv(); !ok || v != 1 {
+		t.Fatalf("expected buffered item after close, got %d (ok=%v)", v, ok)
+	}
+	if _, ok := c.Recv(); ok {
+		t.Fatal("expected ok=false after close")
+	}
+	if _, err := c.RecvCtx(context.Background()); !errors.Is(err, channel.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestChannelRecvCtx(t *testing.T) {
+	c := channel.NewChannel[int]()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.RecvCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestChannelOut(t *testing.T) {
+	c := channel.NewChannel[int]()
+	const n = 10000
+
+	var wg sync.WaitGroup
+	for p := 0; p < 4; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n/4; i++ {
+				c.Send(i)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		c.Close()
+	}()
+
+	count := 0
+	channel.ConsumeWithCtx(context.Background(), c.Out(), func(int) bool {
+		count++
+		return true
+	})
+	if count != n {
+		t.Fatalf("expected %d items, got %d", n, count)
+	}
+}
+
+func TestChannelOutKeepsUnreadItems(t *testing.T) {
+	c := channel.NewChannel[int]()
+	out := c.Out()
+	for i := 0; i < 5; i++ {
+		c.Send(i)
+	}
+	// Give the pump time to offer the head to an Out reader that never comes.
+	time.Sleep(10 * time.Millisecond)
+	if n := c.Len(); n != 5 {
+		t.Fatalf("expected the offered item to stay counted, got len %d", n)
+	}
+	if v, ok := c.TryRecv(); !ok || v != 0 {
+		t.Fatalf("expected TryRecv to take the offered head 0, got %d (ok=%v)", v, ok)
+	}
+	if v := <-out; v != 1 {
+		t.Fatalf("expected Out to continue with 1, got %d", v)
+	}
+
+	c.Close()
+	for i := 2; i < 5; i++ {
+		if v, ok := c.Recv(); !ok || v != i {
+			t.Fatalf("expected %d, got %d (ok=%v)", i, v, ok)
+		}
+	}
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("expected every item to have been received once")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Out was not closed after the channel was closed and drained")
+	}
+}
+
+func TestChannelOutAndRecvShareItems(t *testing.T) {
+	c := channel.NewChannel[int]()
+	const n = 10000
+	go func() {
+		for i := 0; i < n; i++ {
+			c.Send(i)
+		}
+		c.Close()
+	}()
+
+	seen := make([]int, n)
+	var mu sync.Mutex
+	record := func(v int) {
+		mu.Lock()
+		seen[v]++
+		mu.Unlock()
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for v := range c.Out() {
+			record(v)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			v, ok := c.Recv()
+			if !ok {
+				return
+			}
+			record(v)
+		}
+	}()
+	wg.Wait()
+
+	for v, count := range seen {
+		if count != 1 {
+			t.Fatalf("item %d was received %d times", v, count)
+		}
+	}
+}
@@ -1,6 +1,24 @@
 package channel
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// ErrStop can be returned by a handler to stop consuming deliberately.
+var ErrStop = errors.New("consume stopped")
+
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler panicked: %v", e.Value)
+}
 
 func ConsumeWithCtx[T any](ctx context.Context, ch <-chan T, handler func(T) bool) {
 	for {
@@ -18,3 +36,78 @@ func ConsumeWithCtx[T any](ctx context.Context, ch <-chan T, handler func(T) boo
 func Consume[T any](ch <-chan T, handler func(T) bool) {
 	ConsumeWithCtx(context.Background(), ch, handler)
 }
+
+// ConsumeErr returns nil once ch is closed, ctx.Err() once ctx is done, or the first error returned by handler
+// (ErrStop for a deliberate stop). A panicking handler stops consumption with a *PanicError.
+func ConsumeErr[T any](ctx context.Context, ch <-chan T, handler func(T) error) error {
+	for {
+		select {
+		case result, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := safeCall(handler, result); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func safeCall[T any](handler func(T) error, v T) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return handler(v)
+}
+
+// ConsumeBatch hands items to handler in batches of at most maxSize, flushing early once maxWait has passed
+// since the first item of the batch. The pending batch is flushed when ch is closed or ctx is done.
+func ConsumeBatch[T any](ctx context.Context, ch <-chan T, maxSize int, maxWait time.Duration, handler func([]T) bool) {
+	if maxSize < 1 {
+		panic("maxSize must be positive")
+	}
+
+	timer := time.NewTimer(maxWait)
+	timer.Stop()
+	defer timer.Stop()
+
+	batch := make([]T, 0, maxSize)
+	flush := func() bool {
+		timer.Stop()
+		if len(batch) == 0 {
+			return true
+		}
+		ok := handler(batch)
+		batch = make([]T, 0, maxSize)
+		return ok
+	}
+
+	for {
+		select {
+		case item, ok := <-ch:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) >= maxSize {
+				if !flush() {
+					return
+				}
+			} else if len(batch) == 1 {
+				timer.Reset(maxWait)
+			}
+		case <-timer.C:
+			if !flush() {
+				return
+			}
+		case <-ctx.Done():
+			flush()
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func TestConsumeBatchSize(t *testing.T) {
+	var sizes []int
+	channel.ConsumeBatch(context.Background(), produce(25), 10, time.Hour, func(batch []int) bool {
+		sizes = append(sizes, len(batch))
+		return true
+	})
+	if len(sizes) != 3 || sizes[0] != 10 || sizes[1] != 10 || sizes[2] != 5 {
+		t.Fatalf("unexpected batch sizes %v", sizes)
+	}
+}
+
+func TestConsumeBatchMaxWait(t *testing.T) {
+	ch := make(chan int)
+	batches := make(chan []int, 2)
+	go channel.ConsumeBatch(context.Background(), ch, 10, 20*time.Millisecond, func(batch []int) bool {
+		batches <- batch
+		return true
+	})
+
+	ch <- 1
+	ch <- 2
+	select {
+	case batch := <-batches:
+		if len(batch) != 2 {
+			t.Fatalf("expected 2 items, got %v", batch)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a flush after maxWait")
+	}
+	close(ch)
+}
+
+func TestConsumeBatchCtxFlush(t *testing.T) {
+	ch := make(chan int)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan []int, 1)
+	go channel.ConsumeBatch(ctx, ch, 10, time.Hour, func(batch []int) bool {
+		done <- batch
+		return true
+	})
+
+	ch <- 1
+	cancel()
+	if batch := <-done; len(batch) != 1 {
+		t.Fatalf("expected final partial flush, got %v", batch)
+	}
+}
+
+func TestConsumeErr(t *testing.T) {
+	if err := channel.ConsumeErr(context.Background(), produce(10), func(int) error { return nil }); err != nil {
+		t.Fatalf("expected nil on close, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := channel.ConsumeErr(ctx, make(chan int), func(int) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	err := channel.ConsumeErr(context.Background(), produce(10), func(v int) error {
+		if v == 3 {
+			return channel.ErrStop
+		}
+		return nil
+	})
+	if !errors.Is(err, channel.ErrStop) {
+		t.Fatalf("expected ErrStop, got %v", err)
+	}
+
+	err = channel.ConsumeErr(context.Background(), produce(10), func(v int) error {
+		if v == 5 {
+			panic("bad message")
+		}
+		return nil
+	})
+	var panicErr *channel.PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "bad message" {
+		t.Fatalf("expected recovered panic, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package channel
+
+import "context"
+
+type DrainStats struct {
+	Handled   int
+	Abandoned int
+}
+
+// Drainable is implemented by Channel, BoundedChannel and PriorityChannel.
+type Drainable[T any] interface {
+	TryRecv() (T, bool)
+	Len() int
+	Close()
+}
+
+var (
+	_ Drainable[any] = (*Channel[any])(nil)
+	_ Drainable[any] = (*BoundedChannel[any])(nil)
+	_ Drainable[any] = (*PriorityChannel[any])(nil)
+)
+
+// Drain hands the items currently buffered in ch to handler without waiting for new ones. It stops once ch
+// is empty, ctx is done or handler returns false, and reports the items left behind as abandoned.
+func Drain[T any](ctx context.Context, ch <-chan T, handler func(T) bool) DrainStats {
+	return drain(ctx, func() (T, bool) {
+		select {
+		case v, ok := <-ch:
+			return v, ok
+		default:
+			var zero T
+			return zero, false
+		}
+	}, func() int { return len(ch) }, handler)
+}
+
+// DrainAndClose closes ch, which must no longer be sent to, and drains it.
+func DrainAndClose[T any](ctx context.Context, ch chan T, handler func(T) bool) DrainStats {
+	close(ch)
+	return Drain(ctx, ch, handler)
+}
+
+// DrainQueue is Drain for the channel types of this package. An item on offer to an Out reader is still in
+// the queue, so it is drained or counted as abandoned like any other.
+func DrainQueue[T any](ctx context.Context, q Drainable[T], handler func(T) bool) DrainStats {
+	return drain(ctx, q.TryRecv, q.Len, handler)
+}
+
+// DrainAndCloseQueue closes q so that the set of items to drain is fixed, then drains it.
+func DrainAndCloseQueue[T any](ctx context.Context, q Drainable[T], handler func(T) bool) DrainStats {
+	q.Close()
+	return DrainQueue(ctx, q, handler)
+}
+
+func drain[T any](ctx context.Context, tryRecv func() (T, bool), remaining func() int, handler func(T) bool) DrainStats {
+	var stats DrainStats
+	for ctx.Err() == nil {
+		v, ok := tryRecv()
+		if !ok {
+			break
+		}
+		stats.Handled++
+		if !handler(v) {
+			break
+		}
+	}
+	stats.Abandoned = remaining()
+	return stats
+}
@@ -0,0 +1,79 @@
+package channel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func TestDrainAndClose(t *testing.T) {
+	ch := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		ch <- i
+	}
+	stats := channel.DrainAndClose(context.Background(), ch, func(v int) bool { return v < 6 })
+	if stats.Handled != 7 || stats.Abandoned != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestDrainQueueDeadline(t *testing.T) {
+	c := channel.NewChannel[int]()
+	for i := 0; i < 10; i++ {
+		c.Send(i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stats := channel.DrainAndCloseQueue(ctx, c, func(v int) bool {
+		if v == 3 {
+			cancel()
+		}
+		return true
+	})
+	if stats.Handled != 4 || stats.Abandoned != 6 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if !c.Closed() {
+		t.Fatal("expected channel to be closed")
+	}
+}
+
+func TestDrainQueueWithOut(t *testing.T) {
+	c := channel.NewChannel[int]()
+	b := channel.NewBoundedChannel[int](10, channel.OverflowBlock)
+	p := channel.NewPriorityChannel[int](2)
+	queues := map[string]struct {
+		q    channel.Drainable[int]
+		send func(int)
+		out  func() <-chan int
+	}{
+		"channel":  {c, c.Send, c.Out},
+		"bounded":  {b, func(v int) { b.Send(v) }, b.Out},
+		"priority": {p, func(v int) { p.Send(v, v%2) }, p.Out},
+	}
+	for name, q := range queues {
+		t.Run(name, func(t *testing.T) {
+			out := q.out()
+			for i := 0; i < 5; i++ {
+				q.send(i)
+			}
+			// Let the pump offer an item to an Out reader that never comes.
+			time.Sleep(10 * time.Millisecond)
+
+			stats := channel.DrainQueue(context.Background(), q.q, func(int) bool { return false })
+			if stats.Handled != 1 || stats.Abandoned != 4 {
+				t.Fatalf("expected 1 item handled and 4 abandoned, got %+v", stats)
+			}
+			time.Sleep(10 * time.Millisecond)
+			stats = channel.DrainAndCloseQueue(context.Background(), q.q, func(int) bool { return true })
+			if stats.Handled != 4 || stats.Abandoned != 0 {
+				t.Fatalf("expected the other 4 items handled, got %+v", stats)
+			}
+			if _, ok := <-out; ok {
+				t.Fatal("expected Out to be closed after the drain")
+			}
+		})
+	}
+}
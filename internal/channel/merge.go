@@ -0,0 +1,62 @@
+package channel
+
+import (
+	"context"
+	"sync"
+)
+
+// Merge forwards every input to one channel, closed once all inputs are closed or ctx is done.
+func Merge[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	wg := &sync.WaitGroup{}
+	for _, ch := range chans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ConsumeWithCtx(ctx, ch, func(v T) bool {
+				return sendCtx(ctx, out, v)
+			})
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Tee copies every item of in to n channels. Each item is delivered to all outputs before the next
+// one is read, so the slowest reader sets the pace.
+func Tee[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	outs := make([]chan T, n)
+	ret := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		ret[i] = outs[i]
+	}
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+		ConsumeWithCtx(ctx, in, func(v T) bool {
+			for _, out := range outs {
+				if !sendCtx(ctx, out, v) {
+					return false
+				}
+			}
+			return true
+		})
+	}()
+	return ret
+}
+
+func sendCtx[T any](ctx context.Context, ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,91 @@
+package channel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func produce(n int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for i := 0; i < n; i++ {
+			ch <- i
+		}
+	}()
+	return ch
+}
+
+func TestMerge(t *testing.T) {
+	out := channel.Merge(context.Background(), produce(100), produce(200), produce(300))
+
+	sum := 0
+	channel.Consume(out, func(int) bool {
+		sum++
+		return true
+	})
+	if sum != 600 {
+		t.Fatalf("expected 600 items, got %d", sum)
+	}
+}
+
+func TestTee(t *testing.T) {
+	outs := channel.Tee(context.Background(), produce(100), 3)
+
+	counts := make([]int, len(outs))
+	done := make(chan struct{})
+	for i, out := range outs {
+		go func() {
+			channel.Consume(out, func(int) bool {
+				counts[i]++
+				return true
+			})
+			done <- struct{}{}
+		}()
+	}
+	for range outs {
+		<-done
+	}
+	for i, c := range counts {
+		if c != 100 {
+			t.Fatalf("output %d: expected 100 items, got %d", i, c)
+		}
+	}
+}
+
+func TestBroadcasterUnsubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan int)
+	b := channel.NewBroadcaster(ctx, in)
+	a := b.Subscribe(0)
+	stuck := b.Subscribe(0)
+
+	in <- 1
+	// Nobody reads stuck, so the delivery of 1 may be blocked on it until it is unsubscribed.
+	if !b.Unsubscribe(stuck) {
+		t.Fatal("expected stuck to be subscribed")
+	}
+	if _, ok := <-stuck; ok {
+		t.Fatal("expected unsubscribed channel to be closed")
+	}
+	if v := <-a; v != 1 {
+		t.Fatalf("expected 1, got %d", v)
+	}
+
+	in <- 2
+	if v := <-a; v != 2 {
+		t.Fatalf("expected 2, got %d", v)
+	}
+
+	close(in)
+	if _, ok := <-a; ok {
+		t.Fatal("expected subscriber to be closed with the input")
+	}
+	if _, ok := <-b.Subscribe(1); ok {
+		t.Fatal("expected closed channel after stop")
+	}
+}
@@ -0,0 +1,19 @@
+package channel
+
+import "errors"
+
+var ErrFull = errors.New("channel full")
+
+// OverflowPolicy decides what happens to an item sent to a full buffer.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for room.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest discards the item being sent.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest buffered item to make room.
+	OverflowDropOldest
+	// OverflowError rejects the item with ErrFull.
+	OverflowError
+)
@@ -0,0 +1,110 @@
+package channel
+
+import (
+	"context"
+	"sync"
+)
+
+// ConsumeParallel runs handler on the given number of goroutines. It stops once ch is closed, ctx is done
+// or any handler returns false, and returns after all in-flight handlers have finished.
+func ConsumeParallel[T any](ctx context.Context, ch <-chan T, workers int, handler func(T) bool) {
+	if workers < 1 {
+		panic("workers must be positive")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ConsumeWithCtx(ctx, ch, func(v T) bool {
+				if !handler(v) {
+					cancel()
+					return false
+				}
+				return true
+			})
+		}()
+	}
+	wg.Wait()
+}
+
+// ParallelMap applies fn to the items of in on the given number of goroutines. Results are emitted as soon
+// as they are ready; use ParallelMapOrdered to keep input order. The output is closed after all workers have returned.
+func ParallelMap[T any, R any](ctx context.Context, in <-chan T, workers int, fn func(T) R) <-chan R {
+	if workers < 1 {
+		panic("workers must be positive")
+	}
+
+	out := make(chan R)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ConsumeWithCtx(ctx, in, func(v T) bool {
+				return sendCtx(ctx, out, fn(v))
+			})
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// ParallelMapOrdered is ParallelMap emitting results in input order. At most workers items are in flight
+// ahead of the oldest unfinished one.
+func ParallelMapOrdered[T any, R any](ctx context.Context, in <-chan T, workers int, fn func(T) R) <-chan R {
+	if workers < 1 {
+		panic("workers must be positive")
+	}
+
+	type job struct {
+		v    T
+		slot chan R
+	}
+
+	out := make(chan R)
+	jobs := make(chan job)
+	slots := make(chan chan R, workers)
+
+	go func() {
+		defer close(jobs)
+		defer close(slots)
+		ConsumeWithCtx(ctx, in, func(v T) bool {
+			j := job{v: v, slot: make(chan R, 1)}
+			return sendCtx(ctx, slots, j.slot) && sendCtx(ctx, jobs, j)
+		})
+	}()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ConsumeWithCtx(ctx, jobs, func(j job) bool {
+				j.slot <- fn(j.v)
+				return true
+			})
+		}()
+	}
+
+	go func() {
+		defer close(out)
+		defer wg.Wait()
+		ConsumeWithCtx(ctx, slots, func(slot chan R) bool {
+			select {
+			case r := <-slot:
+				return sendCtx(ctx, out, r)
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return out
+}
@@ -0,0 +1,67 @@
+package channel_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func TestConsumeParallelStops(t *testing.T) {
+	ch := make(chan int)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case ch <- i:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var inFlight, handled atomic.Int32
+	channel.ConsumeParallel(context.Background(), ch, 4, func(v int) bool {
+		inFlight.Add(1)
+		defer inFlight.Add(-1)
+		time.Sleep(time.Millisecond)
+		return handled.Add(1) < 20
+	})
+	if n := inFlight.Load(); n != 0 {
+		t.Fatalf("expected no in-flight handlers after return, got %d", n)
+	}
+}
+
+func TestParallelMapOrdered(t *testing.T) {
+	out := channel.ParallelMapOrdered(context.Background(), produce(200), 8, func(v int) int {
+		time.Sleep(time.Duration(v%5) * time.Millisecond)
+		return v * 2
+	})
+
+	next := 0
+	channel.Consume(out, func(v int) bool {
+		if v != next*2 {
+			t.Fatalf("expected %d, got %d", next*2, v)
+		}
+		next++
+		return true
+	})
+	if next != 200 {
+		t.Fatalf("expected 200 results, got %d", next)
+	}
+}
+
+func TestParallelMapCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+	out := channel.ParallelMap(ctx, in, 4, func(v int) int { return v })
+
+	in <- 1
+	<-out
+	cancel()
+	for range out {
+	}
+}
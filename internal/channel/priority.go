@@ -0,0 +1,147 @@
+package channel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/structure"
+)
+
+type priorityItem[T any] struct {
+	v    T
+	sent time.Time
+}
+
+// PriorityChannel is an unbounded channel with a fixed number of priority levels, 0 being the highest.
+// Receivers get the oldest item of the highest non-empty level.
+type PriorityChannel[T any] struct {
+	mu     *sync.Mutex
+	levels []*structure.Queue[priorityItem[T]]
+	size   int
+	aging  time.Duration
+	closed bool
+	recv   *receiver[T]
+}
+
+func NewPriorityChannel[T any](levels int) *PriorityChannel[T] {
+	return NewAgingPriorityChannel[T](levels, 0)
+}
+
+// NewAgingPriorityChannel promotes a waiting item by one level for every aging interval it has spent
+// in the channel, so bulk traffic cannot be starved forever. Zero disables aging.
+func NewAgingPriorityChannel[T any](levels int, aging time.Duration) *PriorityChannel[T] {
+	if levels < 1 {
+		panic("levels must be positive")
+	}
+	if aging < 0 {
+		panic("aging must not be negative")
+	}
+	c := &PriorityChannel[T]{
+		mu:     &sync.Mutex{},
+		levels: make([]*structure.Queue[priorityItem[T]], levels),
+		aging:  aging,
+	}
+	for i := range c.levels {
+		c.levels[i] = structure.NewQueue[priorityItem[T]]()
+	}
+	c.recv = newReceiver[T](c.mu, c)
+	return c
+}
+
+func (c *PriorityChannel[T]) Send(v T, priority int) {
+	if priority < 0 || priority >= len(c.levels) {
+		panic("priority out of range")
+	}
+	item := priorityItem[T]{v: v}
+	if c.aging > 0 {
+		item.sent = time.Now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		panic("send on closed channel")
+	}
+	c.levels[priority].Enqueue(item)
+	c.size++
+	c.recv.notifyLocked()
+}
+
+func (c *PriorityChannel[T]) TryRecv() (T, bool) {
+	return c.recv.tryRecv()
+}
+
+func (c *PriorityChannel[T]) Recv() (T, bool) {
+	v, err := c.RecvCtx(context.Background())
+	return v, err == nil
+}
+
+// RecvCtx returns ErrClosed once the channel is closed and drained, or ctx.Err() if ctx is done first.
+func (c *PriorityChannel[T]) RecvCtx(ctx context.Context) (T, error) {
+	return c.recv.recvCtx(ctx)
+}
+
+// Out returns a channel fed from c in priority order, closed once c is closed and drained. An item stays in
+// c, counted by Len and available to every other receive, until an Out reader has taken it; a
+// higher-priority item sent in the meantime is offered in its place. Len may still count an item for a
+// moment after an Out reader has received it.
+func (c *PriorityChannel[T]) Out() <-chan T {
+	return c.recv.pump()
+}
+
+// Close is idempotent; buffered items remain receivable.
+func (c *PriorityChannel[T]) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	c.recv.notifyLocked()
+}
+
+func (c *PriorityChannel[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *PriorityChannel[T]) closedLocked() bool {
+	return c.closed
+}
+
+func (c *PriorityChannel[T]) headLocked() (T, func(), bool) {
+	best := -1
+	if c.aging > 0 {
+		now := time.Now()
+		bestRank := 0
+		for i, q := range c.levels {
+			item, ok := q.Peek()
+			if !ok {
+				continue
+			}
+			rank := i - int(now.Sub(item.sent)/c.aging)
+			if best < 0 || rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+	} else {
+		for i, q := range c.levels {
+			if q.Len() > 0 {
+				best = i
+				break
+			}
+		}
+	}
+
+	if best < 0 {
+		var zero T
+		return zero, nil, false
+	}
+	item, _ := c.levels[best].Peek()
+	return item.v, func() {
+		c.levels[best].Dequeue()
+		c.size--
+	}, true
+}
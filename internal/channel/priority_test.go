@@ -0,0 +1,65 @@
+package channel_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func TestPriorityChannelOrder(t *testing.T) {
+	c := channel.NewPriorityChannel[string](3)
+	c.Send("bulk-1", 2)
+	c.Send("normal-1", 1)
+	c.Send("bulk-2", 2)
+	c.Send("control-1", 0)
+	c.Send("normal-2", 1)
+	c.Close()
+
+	want := []string{"control-1", "normal-1", "normal-2", "bulk-1", "bulk-2"}
+	i := 0
+	channel.ConsumeWithCtx(context.Background(), c.Out(), func(v string) bool {
+		if v != want[i] {
+			t.Fatalf("expected %s at %d, got %s", want[i], i, v)
+		}
+		i++
+		return true
+	})
+	if i != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), i)
+	}
+}
+
+func TestPriorityChannelAging(t *testing.T) {
+	c := channel.NewAgingPriorityChannel[string](3, 10*time.Millisecond)
+	c.Send("bulk", 2)
+	time.Sleep(35 * time.Millisecond)
+	c.Send("control", 0)
+
+	if v, _ := c.Recv(); v != "bulk" {
+		t.Fatalf("expected aged bulk item first, got %s", v)
+	}
+	if v, _ := c.Recv(); v != "control" {
+		t.Fatalf("expected control item, got %s", v)
+	}
+}
+
+func TestPriorityChannelOutOffersNewHead(t *testing.T) {
+	c := channel.NewPriorityChannel[string](2)
+	out := c.Out()
+	c.Send("bulk", 1)
+	// Let the pump offer bulk before control arrives.
+	time.Sleep(10 * time.Millisecond)
+	c.Send("control", 0)
+
+	if v := <-out; v != "control" {
+		t.Fatalf("expected control to replace the offered bulk, got %s", v)
+	}
+	if v, ok := c.TryRecv(); !ok || v != "bulk" {
+		t.Fatalf("expected bulk to stay queued, got %s (ok=%v)", v, ok)
+	}
+	if v, ok := c.TryRecv(); ok {
+		t.Fatalf("expected nothing left, got %s", v)
+	}
+}
@@ -0,0 +1,153 @@
+package channel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dsx137/gg-kit/internal/lang"
+)
+
+// source is the buffer behind a receiver. Its methods are called with the receiver's mutex held.
+type source[T any] interface {
+	// headLocked returns the item a receive would take next and a function removing exactly that item.
+	headLocked() (v T, take func(), ok bool)
+	closedLocked() bool
+}
+
+// receiver implements the receiving side shared by Channel, BoundedChannel and PriorityChannel: TryRecv,
+// RecvCtx and the Out pump.
+//
+// The pump offers the head on out without removing it, and only takes it once the send went through, so
+// an item waiting for an Out reader is still counted by Len and can still be received, drained or failed.
+// Every other removal claims the buffer first, which makes the pump withdraw its offer and step aside.
+type receiver[T any] struct {
+	mu       *sync.Mutex
+	src      source[T]
+	wait     chan struct{}
+	out      chan T
+	outOnce  *sync.Once
+	offering bool
+	offered  chan struct{}
+	claims   int
+}
+
+func newReceiver[T any](mu *sync.Mutex, src source[T]) *receiver[T] {
+	return &receiver[T]{
+		mu:      mu,
+		src:     src,
+		outOnce: &sync.Once{},
+	}
+}
+
+// notifyLocked wakes receivers and the pump after an item was added or the buffer was closed.
+func (r *receiver[T]) notifyLocked() {
+	r.wait = lang.Wake(r.wait)
+}
+
+func (r *receiver[T]) waitLocked() chan struct{} {
+	if r.wait == nil {
+		r.wait = make(chan struct{})
+	}
+	return r.wait
+}
+
+// takeLocked removes and returns the head, withdrawing it from the pump first if it is on offer.
+func (r *receiver[T]) takeLocked() (T, bool) {
+	r.claims++
+	for r.offering {
+		r.notifyLocked()
+		if r.offered == nil {
+			r.offered = make(chan struct{})
+		}
+		offered := r.offered
+		r.mu.Unlock()
+		<-offered
+		r.mu.Lock()
+	}
+
+	v, take, ok := r.src.headLocked()
+	if ok {
+		take()
+	}
+	r.claims--
+	if r.claims == 0 && r.out != nil {
+		// Let the pump make its next offer.
+		r.notifyLocked()
+	}
+	return v, ok
+}
+
+func (r *receiver[T]) tryRecv() (T, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.takeLocked()
+}
+
+func (r *receiver[T]) recvCtx(ctx context.Context) (T, error) {
+	for {
+		r.mu.Lock()
+		if v, ok := r.takeLocked(); ok {
+			r.mu.Unlock()
+			return v, nil
+		}
+		if r.src.closedLocked() {
+			r.mu.Unlock()
+			var zero T
+			return zero, ErrClosed
+		}
+		wait := r.waitLocked()
+		r.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// pump starts, on first use, the goroutine feeding out. It exits and closes out once the buffer is closed
+// and empty, whoever emptied it.
+func (r *receiver[T]) pump() <-chan T {
+	r.outOnce.Do(func() {
+		r.mu.Lock()
+		r.out = make(chan T)
+		r.mu.Unlock()
+		go r.run()
+	})
+	return r.out
+}
+
+func (r *receiver[T]) run() {
+	defer close(r.out)
+
+	r.mu.Lock()
+	for {
+		v, take, ok := r.src.headLocked()
+		if r.claims > 0 || !ok {
+			if !ok && r.src.closedLocked() {
+				r.mu.Unlock()
+				return
+			}
+			wait := r.waitLocked()
+			r.mu.Unlock()
+			<-wait
+			r.mu.Lock()
+			continue
+		}
+
+		wait := r.waitLocked()
+		r.offering = true
+		r.mu.Unlock()
+		select {
+		case r.out <- v:
+			r.mu.Lock()
+			take()
+		case <-wait:
+			r.mu.Lock()
+		}
+		r.offering = false
+		r.offered = lang.Wake(r.offered)
+	}
+}
@@ -0,0 +1,179 @@
+package channel
+
+import (
+	"context"
+	"sync"
+)
+
+// Future is the pending result of a request. It resolves exactly once.
+type Future[T any] struct {
+	mu       *sync.Mutex
+	done     chan struct{}
+	resolved bool
+	v        T
+	err      error
+	stop     func() bool
+}
+
+func newFuture[T any]() *Future[T] {
+	return &Future[T]{
+		mu:   &sync.Mutex{},
+		done: make(chan struct{}),
+	}
+}
+
+func (f *Future[T]) resolve(v T, err error) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.resolved {
+		return false
+	}
+	f.resolved = true
+	f.v, f.err = v, err
+	close(f.done)
+	if f.stop != nil {
+		f.stop()
+	}
+	return true
+}
+
+// watch fails the future once ctx is done.
+func (f *Future[T]) watch(ctx context.Context) {
+	stop := context.AfterFunc(ctx, func() {
+		var zero T
+		f.resolve(zero, ctx.Err())
+	})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.resolved {
+		stop()
+		return
+	}
+	f.stop = stop
+}
+
+// Done is closed once the future has resolved.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+func (f *Future[T]) Get() (T, error) {
+	<-f.done
+	return f.v, f.err
+}
+
+// Wait gives up with ctx.Err() if ctx is done first; the future itself stays pending.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.v, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Request is what the consumer of a RequestChannel receives. Exactly one of Reply and Fail takes effect.
+type Request[Req any, Resp any] struct {
+	Value  Req
+	ctx    context.Context
+	future *Future[Resp]
+}
+
+// Context is the sender's context; once it is done the reply is no longer awaited.
+func (r *Request[Req, Resp]) Context() context.Context {
+	return r.ctx
+}
+
+// Reply reports whether resp was delivered, which is false if the request already failed or was answered.
+func (r *Request[Req, Resp]) Reply(resp Resp) bool {
+	return r.future.resolve(resp, nil)
+}
+
+func (r *Request[Req, Resp]) Fail(err error) bool {
+	var zero Resp
+	return r.future.resolve(zero, err)
+}
+
+// RequestChannel carries requests to a consumer and their replies back to the senders.
+type RequestChannel[Req any, Resp any] struct {
+	mu       *sync.Mutex
+	requests *Channel[*Request[Req, Resp]]
+	closed   bool
+}
+
+func NewRequestChannel[Req any, Resp any]() *RequestChannel[Req, Resp] {
+	return &RequestChannel[Req, Resp]{
+		mu:       &sync.Mutex{},
+		requests: NewChannel[*Request[Req, Resp]](),
+	}
+}
+
+// Send never blocks. The future fails with ctx.Err() once ctx is done, or with ErrClosed if the channel
+// is closed before the request was received.
+func (c *RequestChannel[Req, Resp]) Send(ctx context.Context, req Req) (*Future[Resp], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r := &Request[Req, Resp]{
+		Value:  req,
+		ctx:    ctx,
+		future: newFuture[Resp](),
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	c.requests.Send(r)
+	c.mu.Unlock()
+
+	r.future.watch(ctx)
+	return r.future, nil
+}
+
+// Call sends req and waits for its reply.
+func (c *RequestChannel[Req, Resp]) Call(ctx context.Context, req Req) (Resp, error) {
+	f, err := c.Send(ctx, req)
+	if err != nil {
+		var zero Resp
+		return zero, err
+	}
+	return f.Get()
+}
+
+// Out feeds the consumer, e.g. through ConsumeWithCtx, and is closed once the channel is closed and drained.
+func (c *RequestChannel[Req, Resp]) Out() <-chan *Request[Req, Resp] {
+	return c.requests.Out()
+}
+
+func (c *RequestChannel[Req, Resp]) Recv() (*Request[Req, Resp], bool) {
+	return c.requests.Recv()
+}
+
+func (c *RequestChannel[Req, Resp]) RecvCtx(ctx context.Context) (*Request[Req, Resp], error) {
+	return c.requests.RecvCtx(ctx)
+}
+
+// Close fails every request that has not been received yet with ErrClosed, including one still on offer
+// to an Out reader.
+func (c *RequestChannel[Req, Resp]) Close() {
+	c.mu.Lock()
+	c.closed = true
+	c.requests.Close()
+	c.mu.Unlock()
+
+	for {
+		r, ok := c.requests.TryRecv()
+		if !ok {
+			return
+		}
+		r.Fail(ErrClosed)
+	}
+}
+
+func (c *RequestChannel[Req, Resp]) Len() int {
+	return c.requests.Len()
+}
@@ -0,0 +1,79 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func TestRequestChannelReply(t *testing.T) {
+	rc := channel.NewRequestChannel[string, string]()
+	go channel.ConsumeWithCtx(context.Background(), rc.Out(), func(r *channel.Request[string, string]) bool {
+		r.Reply(strings.ToUpper(r.Value))
+		return true
+	})
+	defer rc.Close()
+
+	for _, in := range []string{"a", "b", "c"} {
+		out, err := rc.Call(context.Background(), in)
+		if err != nil || out != strings.ToUpper(in) {
+			t.Fatalf("expected %q, got %q (err=%v)", strings.ToUpper(in), out, err)
+		}
+	}
+}
+
+func TestRequestChannelCancel(t *testing.T) {
+	rc := channel.NewRequestChannel[int, int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	f, err := rc.Send(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := f.Get(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	r, _ := rc.Recv()
+	if r.Reply(2) {
+		t.Fatal("expected reply to a cancelled request to be ignored")
+	}
+}
+
+func TestRequestChannelClose(t *testing.T) {
+	rc := channel.NewRequestChannel[int, int]()
+	f, _ := rc.Send(context.Background(), 1)
+	rc.Close()
+
+	if _, err := f.Get(); !errors.Is(err, channel.ErrClosed) {
+		t.Fatalf("expected ErrClosed for a pending request, got %v", err)
+	}
+	if _, err := rc.Send(context.Background(), 2); !errors.Is(err, channel.ErrClosed) {
+		t.Fatalf("expected ErrClosed after close, got %v", err)
+	}
+}
+
+func TestRequestChannelCloseWithOut(t *testing.T) {
+	rc := channel.NewRequestChannel[int, int]()
+	out := rc.Out()
+	f, _ := rc.Send(context.Background(), 1)
+	// Let the pump offer the request to a consumer that is already gone.
+	time.Sleep(10 * time.Millisecond)
+	rc.Close()
+
+	select {
+	case <-f.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("a request waiting on Out was not failed by Close")
+	}
+	if _, err := f.Get(); !errors.Is(err, channel.ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if _, ok := <-out; ok {
+		t.Fatal("expected Out to be closed once the failed requests were drained")
+	}
+}
@@ -0,0 +1,136 @@
+package channel
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/dsx137/gg-kit/internal/lang"
+)
+
+// Selection describes what a Selector received. Closed is set when Source was closed, in which case
+// Value is the zero value and Source has been removed from the selector.
+type Selection[T any] struct {
+	Source <-chan T
+	Value  T
+	Closed bool
+}
+
+// Selector waits on a set of channels that may change while it is waiting.
+type Selector[T any] struct {
+	mu      *sync.Mutex
+	chans   []<-chan T
+	changed chan struct{}
+}
+
+func NewSelector[T any](chans ...<-chan T) *Selector[T] {
+	return &Selector[T]{
+		mu:    &sync.Mutex{},
+		chans: slices.Clone(chans),
+	}
+}
+
+// Add wakes up a pending Select so that it also waits on ch.
+func (s *Selector[T]) Add(ch <-chan T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chans = append(s.chans, ch)
+	s.notifyLocked()
+}
+
+func (s *Selector[T]) Remove(ch <-chan T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.Index(s.chans, ch)
+	if i < 0 {
+		return false
+	}
+	s.chans = slices.Delete(s.chans, i, i+1)
+	s.notifyLocked()
+	return true
+}
+
+func (s *Selector[T]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.chans)
+}
+
+// Select blocks until one of the channels is ready or ctx is done. With no channels it waits for Add.
+func (s *Selector[T]) Select(ctx context.Context) (Selection[T], error) {
+	for {
+		s.mu.Lock()
+		chans := slices.Clone(s.chans)
+		changed := s.changedLocked()
+		s.mu.Unlock()
+
+		var sel Selection[T]
+		var ok bool
+		switch len(chans) {
+		case 0:
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return sel, ctx.Err()
+			}
+		case 1:
+			select {
+			case sel.Value, ok = <-chans[0]:
+				sel.Source = chans[0]
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return sel, ctx.Err()
+			}
+		case 2:
+			select {
+			case sel.Value, ok = <-chans[0]:
+				sel.Source = chans[0]
+			case sel.Value, ok = <-chans[1]:
+				sel.Source = chans[1]
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return sel, ctx.Err()
+			}
+		default:
+			cases := make([]reflect.SelectCase, len(chans)+2)
+			cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+			cases[1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(changed)}
+			for i, ch := range chans {
+				cases[i+2] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
+			}
+			chosen, v, recvOK := reflect.Select(cases)
+			switch chosen {
+			case 0:
+				return sel, ctx.Err()
+			case 1:
+				continue
+			}
+			sel.Source, ok = chans[chosen-2], recvOK
+			if ok {
+				// A nil interface value fails the assertion and correctly leaves the zero value.
+				sel.Value, _ = v.Interface().(T)
+			}
+		}
+
+		if !ok {
+			sel.Closed = true
+			s.Remove(sel.Source)
+		}
+		return sel, nil
+	}
+}
+
+func (s *Selector[T]) changedLocked() chan struct{} {
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+	return s.changed
+}
+
+func (s *Selector[T]) notifyLocked() {
+	s.changed = lang.Wake(s.changed)
+}
@@ -0,0 +1,63 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func TestSelector(t *testing.T) {
+	for _, n := range []int{1, 2, 5} {
+		chans := make([]chan int, n)
+		s := channel.NewSelector[int]()
+		for i := range chans {
+			chans[i] = make(chan int, 1)
+			s.Add(chans[i])
+		}
+
+		for i, ch := range chans {
+			ch <- i
+			sel, err := s.Select(context.Background())
+			if err != nil || sel.Closed || sel.Value != i || sel.Source != (<-chan int)(ch) {
+				t.Fatalf("n=%d: unexpected selection %+v (err=%v)", n, sel, err)
+			}
+		}
+		for range chans {
+			close(chans[0])
+			sel, err := s.Select(context.Background())
+			if err != nil || !sel.Closed || sel.Source != (<-chan int)(chans[0]) {
+				t.Fatalf("n=%d: expected close of first channel, got %+v (err=%v)", n, sel, err)
+			}
+			chans = chans[1:]
+		}
+		if s.Len() != 0 {
+			t.Fatalf("n=%d: expected closed channels to be removed, %d left", n, s.Len())
+		}
+	}
+}
+
+func TestSelectorAddWhileWaiting(t *testing.T) {
+	s := channel.NewSelector[string]()
+	got := make(chan channel.Selection[string])
+	go func() {
+		sel, _ := s.Select(context.Background())
+		got <- sel
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	ch := make(chan string, 1)
+	ch <- "late"
+	s.Add(ch)
+	if sel := <-got; sel.Value != "late" {
+		t.Fatalf("expected value from added channel, got %+v", sel)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.Select(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
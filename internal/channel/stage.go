@@ -0,0 +1,115 @@
+package channel
+
+import "context"
+
+// stage runs step for every item of in on its own goroutine. The output is closed once in is closed,
+// ctx is done or step returns false.
+func stage[T any, U any](ctx context.Context, in <-chan T, step func(v T, emit func(U) bool) bool) <-chan U {
+	out := make(chan U)
+	emit := func(u U) bool { return sendCtx(ctx, out, u) }
+	go func() {
+		defer close(out)
+		ConsumeWithCtx(ctx, in, func(v T) bool {
+			return step(v, emit)
+		})
+	}()
+	return out
+}
+
+func Map[T any, U any](ctx context.Context, in <-chan T, fn func(T) U) <-chan U {
+	return stage(ctx, in, func(v T, emit func(U) bool) bool {
+		return emit(fn(v))
+	})
+}
+
+func Filter[T any](ctx context.Context, in <-chan T, pred func(T) bool) <-chan T {
+	return stage(ctx, in, func(v T, emit func(T) bool) bool {
+		return !pred(v) || emit(v)
+	})
+}
+
+func FlatMap[T any, U any](ctx context.Context, in <-chan T, fn func(T) []U) <-chan U {
+	return stage(ctx, in, func(v T, emit func(U) bool) bool {
+		for _, u := range fn(v) {
+			if !emit(u) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Take forwards the first n items and then closes its output. It then consumes the rest of in, reading and
+// discarding items until in is closed or ctx is done, so upstream stages are never left blocked on a send.
+// An unbounded source is therefore drained for as long as ctx lives: cancel ctx once the output is done
+// with to stop it.
+func Take[T any](ctx context.Context, in <-chan T, n int) <-chan T {
+	out := make(chan T)
+	go func() {
+		if n > 0 {
+			taken := 0
+			ConsumeWithCtx(ctx, in, func(v T) bool {
+				if !sendCtx(ctx, out, v) {
+					return false
+				}
+				taken++
+				return taken < n
+			})
+		}
+		close(out)
+		ConsumeWithCtx(ctx, in, func(T) bool { return true })
+	}()
+	return out
+}
+
+func Skip[T any](ctx context.Context, in <-chan T, n int) <-chan T {
+	skipped := 0
+	return stage(ctx, in, func(v T, emit func(T) bool) bool {
+		if skipped < n {
+			skipped++
+			return true
+		}
+		return emit(v)
+	})
+}
+
+// Distinct forwards the first occurrence of every item. It remembers every item it has seen.
+func Distinct[T comparable](ctx context.Context, in <-chan T) <-chan T {
+	seen := make(map[T]struct{})
+	return stage(ctx, in, func(v T, emit func(T) bool) bool {
+		if _, ok := seen[v]; ok {
+			return true
+		}
+		seen[v] = struct{}{}
+		return emit(v)
+	})
+}
+
+// Window emits the items of in as windows of size items, starting a new window every step items.
+// step == size gives tumbling windows, step < size sliding ones. Incomplete windows are not emitted.
+func Window[T any](ctx context.Context, in <-chan T, size int, step int) <-chan []T {
+	if size < 1 || step < 1 {
+		panic("size and step must be positive")
+	}
+	buf := make([]T, 0, size)
+	skip := 0
+	return stage(ctx, in, func(v T, emit func([]T) bool) bool {
+		if skip > 0 {
+			skip--
+			return true
+		}
+		buf = append(buf, v)
+		if len(buf) < size {
+			return true
+		}
+		window := make([]T, size)
+		copy(window, buf)
+		if step < size {
+			buf = append(buf[:0], buf[step:]...)
+		} else {
+			buf = buf[:0]
+			skip = step - size
+		}
+		return emit(window)
+	})
+}
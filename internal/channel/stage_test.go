@@ -0,0 +1,80 @@
+package channel_test
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/channel"
+)
+
+func collect[T any](ch <-chan T) []T {
+	var out []T
+	channel.Consume(ch, func(v T) bool {
+		out = append(out, v)
+		return true
+	})
+	return out
+}
+
+func TestStagePipeline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	evens := channel.Filter(ctx, produce(20), func(v int) bool { return v%2 == 0 })
+	pairs := channel.FlatMap(ctx, evens, func(v int) []int { return []int{v / 4, v / 4} })
+	unique := channel.Distinct(ctx, pairs)
+	labels := channel.Map(ctx, channel.Skip(ctx, unique, 1), func(v int) string { return fmt.Sprint(v) })
+
+	got := fmt.Sprint(collect(channel.Take(ctx, labels, 3)))
+	if got != "[1 2 3]" {
+		t.Fatalf("unexpected pipeline output %s", got)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		size, step int
+		want       string
+	}{
+		{3, 3, "[[0 1 2] [3 4 5]]"},
+		{3, 1, "[[0 1 2] [1 2 3] [2 3 4] [3 4 5] [4 5 6]]"},
+		{2, 3, "[[0 1] [3 4]]"},
+	}
+	for _, c := range cases {
+		got := fmt.Sprint(collect(channel.Window(ctx, produce(7), c.size, c.step)))
+		if got != c.want {
+			t.Fatalf("size %d step %d: expected %s, got %s", c.size, c.step, c.want, got)
+		}
+	}
+}
+
+func TestStageCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	out := channel.Map(ctx, make(chan int), func(v int) int { return v })
+	cancel()
+	if _, ok := <-out; ok {
+		t.Fatal("expected output to be closed after cancel")
+	}
+}
+
+func TestTakeLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for _, n := range []int{0, 3} {
+		got := collect(channel.Take(context.Background(), produce(100), n))
+		if len(got) != n {
+			t.Fatalf("expected %d items, got %d", n, len(got))
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d goroutines after Take, got %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
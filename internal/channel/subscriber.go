@@ -0,0 +1,87 @@
+package channel
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// subscriber owns a buffered channel that is only ever sent to and closed under sendMu.
+type subscriber[T any] struct {
+	ch      chan T
+	done    chan struct{}
+	sendMu  *sync.Mutex
+	closed  bool
+	policy  OverflowPolicy
+	dropped *atomic.Uint64
+}
+
+func newSubscriber[T any](buffer int, policy OverflowPolicy) *subscriber[T] {
+	return &subscriber[T]{
+		ch:      make(chan T, buffer),
+		done:    make(chan struct{}),
+		sendMu:  &sync.Mutex{},
+		policy:  policy,
+		dropped: &atomic.Uint64{},
+	}
+}
+
+// send returns ErrFull under OverflowError, or ctx.Err() if ctx is done while blocked.
+// Sends to a closed subscriber are discarded.
+func (s *subscriber[T]) send(ctx context.Context, v T) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	// An unbuffered channel has nothing to evict, so dropping the oldest degrades to dropping the newest.
+	switch {
+	case s.policy == OverflowDropNewest, s.policy == OverflowDropOldest && cap(s.ch) == 0:
+		select {
+		case s.ch <- v:
+		default:
+			s.dropped.Add(1)
+		}
+		return nil
+	case s.policy == OverflowError:
+		select {
+		case s.ch <- v:
+			return nil
+		default:
+			s.dropped.Add(1)
+			return ErrFull
+		}
+	case s.policy == OverflowDropOldest:
+		for {
+			select {
+			case s.ch <- v:
+				return nil
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+	}
+
+	select {
+	case s.ch <- v:
+		return nil
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close abandons a blocked send and closes the channel. It must be called at most once.
+func (s *subscriber[T]) close() {
+	close(s.done)
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
@@ -0,0 +1,311 @@
+package concurrent
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/lang"
+)
+
+type LockMode int
+
+const (
+	LockWrite LockMode = iota
+	LockRead
+)
+
+func (m LockMode) String() string {
+	if m == LockRead {
+		return "read"
+	}
+	return "write"
+}
+
+// LockInfo describes a goroutine holding or waiting for a key. Duration is measured up to the moment the
+// info was produced.
+type LockInfo[K comparable] struct {
+	Key       K
+	Goroutine int
+	Mode      LockMode
+	Since     time.Time
+	Duration  time.Duration
+}
+
+type LockEventKind int
+
+const (
+	// LockLongHold is reported when a lock is released after, or found held for, longer than the threshold.
+	LockLongHold LockEventKind = iota
+	// LockLongWait is reported when a lock is acquired after waiting longer than the threshold.
+	LockLongWait
+	// LockDeadlock is reported when a goroutine starts waiting for a lock that closes a wait-for cycle.
+	LockDeadlock
+)
+
+func (k LockEventKind) String() string {
+	switch k {
+	case LockLongHold:
+		return "long hold"
+	case LockLongWait:
+		return "long wait"
+	default:
+		return "deadlock"
+	}
+}
+
+// LockEvent is what LockDiagnostics reports. For LockDeadlock, Cycle lists the waits that form the cycle,
+// starting with Info.
+type LockEvent[K comparable] struct {
+	Kind  LockEventKind
+	Info  LockInfo[K]
+	Cycle []LockInfo[K]
+}
+
+type LockSnapshot[K comparable] struct {
+	Holders []LockInfo[K]
+	Waiters []LockInfo[K]
+}
+
+// lockRes identifies a lock: a key or shard of a given locker.
+type lockRes struct {
+	locker any
+	id     any
+}
+
+type lockRecord[K comparable] struct {
+	res   any
+	key   K
+	mode  LockMode
+	since time.Time
+	count int
+}
+
+// LockDiagnostics tracks which goroutine holds or waits for which key of an instrumented keyed locker.
+// Goroutines are identified with lang.GetGoroutineId, so instrumentation costs a stack dump per operation.
+type LockDiagnostics[K comparable] struct {
+	mu        *sync.Mutex
+	holds     map[any]map[int]*lockRecord[K]
+	waits     map[int]*lockRecord[K]
+	threshold time.Duration
+	report    func(LockEvent[K])
+}
+
+// NewLockDiagnostics reports holds and waits longer than threshold (zero disables them) and deadlocks to
+// report, which may be nil if only Dump is used. report runs on the goroutine that triggered the event.
+// Long holds are only noticed when the lock is released, so a holder that never releases goes unreported
+// unless Check is polled, for example by Watch.
+func NewLockDiagnostics[K comparable](threshold time.Duration, report func(LockEvent[K])) *LockDiagnostics[K] {
+	if report == nil {
+		report = func(LockEvent[K]) {}
+	}
+	return &LockDiagnostics[K]{
+		mu:        &sync.Mutex{},
+		holds:     make(map[any]map[int]*lockRecord[K]),
+		waits:     make(map[int]*lockRecord[K]),
+		threshold: threshold,
+		report:    report,
+	}
+}
+
+// waiting records that the current goroutine is about to block on res and returns its goroutine id.
+func (d *LockDiagnostics[K]) waiting(res any, key K, mode LockMode) int {
+	gid := lang.GetGoroutineId()
+	now := time.Now()
+
+	d.mu.Lock()
+	d.waits[gid] = &lockRecord[K]{res: res, key: key, mode: mode, since: now}
+	cycle := d.cycleLocked(gid, now)
+	d.mu.Unlock()
+
+	if cycle != nil {
+		d.report(LockEvent[K]{Kind: LockDeadlock, Info: cycle[0], Cycle: cycle})
+	}
+	return gid
+}
+
+// lock records a blocking acquisition made with lock and returns the goroutine id of the holder.
+func (d *LockDiagnostics[K]) lock(res any, key K, mode LockMode, lock func()) int {
+	gid := d.waiting(res, key, mode)
+	lock()
+	return d.acquired(gid, res, key, mode)
+}
+
+// acquired moves gid from waiting to holding res. gid is 0 if the lock was taken without waiting.
+func (d *LockDiagnostics[K]) acquired(gid int, res any, key K, mode LockMode) int {
+	if gid == 0 {
+		gid = lang.GetGoroutineId()
+	}
+	now := time.Now()
+
+	d.mu.Lock()
+	w := d.waits[gid]
+	delete(d.waits, gid)
+	holders, ok := d.holds[res]
+	if !ok {
+		holders = make(map[int]*lockRecord[K])
+		d.holds[res] = holders
+	}
+	if h, ok := holders[gid]; ok {
+		h.count++
+	} else {
+		holders[gid] = &lockRecord[K]{res: res, key: key, mode: mode, since: now, count: 1}
+	}
+	d.mu.Unlock()
+
+	if w != nil && d.threshold > 0 && now.Sub(w.since) > d.threshold {
+		d.report(LockEvent[K]{Kind: LockLongWait, Info: w.info(gid, now)})
+	}
+	return gid
+}
+
+// abandoned records that gid stopped waiting without getting the lock.
+func (d *LockDiagnostics[K]) abandoned(gid int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.waits, gid)
+}
+
+func (d *LockDiagnostics[K]) released(gid int, res any) {
+	now := time.Now()
+
+	d.mu.Lock()
+	holders := d.holds[res]
+	h, ok := holders[gid]
+	if !ok {
+		d.mu.Unlock()
+		return
+	}
+	h.count--
+	if h.count > 0 {
+		d.mu.Unlock()
+		return
+	}
+	delete(holders, gid)
+	if len(holders) == 0 {
+		delete(d.holds, res)
+	}
+	d.mu.Unlock()
+
+	if d.threshold > 0 && now.Sub(h.since) > d.threshold {
+		d.report(LockEvent[K]{Kind: LockLongHold, Info: h.info(gid, now)})
+	}
+}
+
+// releasedAny releases a hold on res by the current goroutine, or by any holder if it has none, for
+// unlocks made through a sync.Locker.
+func (d *LockDiagnostics[K]) releasedAny(res any) {
+	gid := lang.GetGoroutineId()
+	d.mu.Lock()
+	if _, ok := d.holds[res][gid]; !ok {
+		for holder := range d.holds[res] {
+			gid = holder
+			break
+		}
+	}
+	d.mu.Unlock()
+	d.released(gid, res)
+}
+
+// cycleLocked follows the wait-for graph from gid and returns the waits forming a cycle back to it.
+func (d *LockDiagnostics[K]) cycleLocked(gid int, now time.Time) []LockInfo[K] {
+	visited := map[int]bool{}
+	var path []LockInfo[K]
+	var visit func(g int) bool
+	visit = func(g int) bool {
+		w, ok := d.waits[g]
+		if !ok || visited[g] {
+			return false
+		}
+		visited[g] = true
+		path = append(path, w.info(g, now))
+		for holder, h := range d.holds[w.res] {
+			// Readers only wait for writers.
+			if w.mode == LockRead && h.mode == LockRead {
+				continue
+			}
+			if holder == gid || visit(holder) {
+				return true
+			}
+		}
+		if w.mode == LockRead {
+			// A writer queued first keeps new readers out, so the reader waits for it too, even where the
+			// lock is only read-held.
+			for writer, q := range d.waits {
+				if q.res != w.res || q.mode != LockWrite || q.since.After(w.since) {
+					continue
+				}
+				if writer == gid || visit(writer) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if visit(gid) {
+		return path
+	}
+	return nil
+}
+
+// Dump returns the current holders and waiters, longest first.
+func (d *LockDiagnostics[K]) Dump() LockSnapshot[K] {
+	now := time.Now()
+	var s LockSnapshot[K]
+
+	d.mu.Lock()
+	for _, holders := range d.holds {
+		for gid, h := range holders {
+			s.Holders = append(s.Holders, h.info(gid, now))
+		}
+	}
+	for gid, w := range d.waits {
+		s.Waiters = append(s.Waiters, w.info(gid, now))
+	}
+	d.mu.Unlock()
+
+	byDuration := func(infos []LockInfo[K]) {
+		sort.Slice(infos, func(i, j int) bool { return infos[i].Duration > infos[j].Duration })
+	}
+	byDuration(s.Holders)
+	byDuration(s.Waiters)
+	return s
+}
+
+// Check reports every lock currently held longer than the threshold, which is how hung holders show up.
+func (d *LockDiagnostics[K]) Check() {
+	if d.threshold <= 0 {
+		return
+	}
+	for _, h := range d.Dump().Holders {
+		if h.Duration > d.threshold {
+			d.report(LockEvent[K]{Kind: LockLongHold, Info: h})
+		}
+	}
+}
+
+// Watch calls Check every interval until ctx is done. A hold still going on is reported on every tick.
+func (d *LockDiagnostics[K]) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.Check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *lockRecord[K]) info(gid int, now time.Time) LockInfo[K] {
+	return LockInfo[K]{
+		Key:       r.key,
+		Goroutine: gid,
+		Mode:      r.mode,
+		Since:     r.since,
+		Duration:  now.Sub(r.since),
+	}
+}
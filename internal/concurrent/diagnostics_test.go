@@ -0,0 +1,174 @@
+package concurrent_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+	"github.com/dsx137/gg-kit/internal/lang"
+)
+
+type instrumentedLocker interface {
+	Lock(key int) func()
+	LockCtx(ctx context.Context, key int) (func(), error)
+	RLock(key int) func()
+	RLockCtx(ctx context.Context, key int) (func(), error)
+}
+
+func instrumentedLockers(d *concurrent.LockDiagnostics[int]) map[string]instrumentedLocker {
+	return map[string]instrumentedLocker{
+		"map":     concurrent.NewMapKeyedLocker[int]().Instrument(d),
+		"sharded": concurrent.NewShardedKeyedLocker(4, func(k int) uint64 { return uint64(k) }).Instrument(d),
+	}
+}
+
+type eventLog struct {
+	mu     sync.Mutex
+	events []concurrent.LockEvent[int]
+}
+
+func (l *eventLog) report(e concurrent.LockEvent[int]) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, e)
+}
+
+func (l *eventLog) of(kind concurrent.LockEventKind) []concurrent.LockEvent[int] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []concurrent.LockEvent[int]
+	for _, e := range l.events {
+		if e.Kind == kind {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestLockDiagnosticsLongHold(t *testing.T) {
+	for name := range instrumentedLockers(nil) {
+		t.Run(name, func(t *testing.T) {
+			log := &eventLog{}
+			d := concurrent.NewLockDiagnostics(10*time.Millisecond, log.report)
+			l := instrumentedLockers(d)[name]
+
+			unlock := l.Lock(1)
+			holders := d.Dump().Holders
+			if len(holders) != 1 || holders[0].Key != 1 || holders[0].Goroutine != lang.GetGoroutineId() {
+				t.Fatalf("unexpected holders %+v", holders)
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			d.Check()
+			unlock()
+
+			holds := log.of(concurrent.LockLongHold)
+			if len(holds) != 2 || holds[1].Info.Duration < 20*time.Millisecond {
+				t.Fatalf("expected long hold from Check and from unlock, got %+v", holds)
+			}
+			if s := d.Dump(); len(s.Holders) != 0 || len(s.Waiters) != 0 {
+				t.Fatalf("expected empty snapshot, got %+v", s)
+			}
+		})
+	}
+}
+
+func TestLockDiagnosticsWatch(t *testing.T) {
+	log := &eventLog{}
+	d := concurrent.NewLockDiagnostics(10*time.Millisecond, log.report)
+	l := concurrent.NewMapKeyedLocker[int]().Instrument(d)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Watch(ctx, 5*time.Millisecond)
+
+	unlock := l.Lock(1)
+	defer unlock()
+	deadline := time.Now().Add(time.Second)
+	for len(log.of(concurrent.LockLongHold)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected Watch to report the hold before it ends")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLockDiagnosticsDeadlock(t *testing.T) {
+	for name := range instrumentedLockers(nil) {
+		t.Run(name, func(t *testing.T) {
+			log := &eventLog{}
+			d := concurrent.NewLockDiagnostics(0, log.report)
+			l := instrumentedLockers(d)[name]
+
+			unlockB := l.Lock(2)
+			aHolds := make(chan struct{})
+			aDone := make(chan struct{})
+			go func() {
+				defer close(aDone)
+				unlockA := l.Lock(1)
+				close(aHolds)
+				l.Lock(2)()
+				unlockA()
+			}()
+			<-aHolds
+			for len(d.Dump().Waiters) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			// This goroutine holds 2 and now waits for 1, held by a goroutine waiting for 2.
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if _, err := l.LockCtx(ctx, 1); err == nil {
+				t.Fatal("expected the deadlocked attempt to time out")
+			}
+			unlockB()
+			<-aDone
+
+			deadlocks := log.of(concurrent.LockDeadlock)
+			if len(deadlocks) != 1 || len(deadlocks[0].Cycle) != 2 {
+				t.Fatalf("expected one two-goroutine cycle, got %+v", deadlocks)
+			}
+			if c := deadlocks[0].Cycle; c[0].Key != 1 || c[1].Key != 2 {
+				t.Fatalf("unexpected cycle %+v", c)
+			}
+		})
+	}
+}
+
+func TestLockDiagnosticsReaderBehindWriter(t *testing.T) {
+	for name := range instrumentedLockers(nil) {
+		t.Run(name, func(t *testing.T) {
+			log := &eventLog{}
+			d := concurrent.NewLockDiagnostics(0, log.report)
+			l := instrumentedLockers(d)[name]
+
+			runlock := l.RLock(1)
+			writerDone := make(chan struct{})
+			go func() {
+				defer close(writerDone)
+				l.Lock(1)()
+			}()
+			for len(d.Dump().Waiters) == 0 {
+				time.Sleep(time.Millisecond)
+			}
+
+			// Read-locking 1 again queues behind the writer, which waits for this goroutine's first read lock.
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			if _, err := l.RLockCtx(ctx, 1); err == nil {
+				t.Fatal("expected the second read lock to wait behind the writer")
+			}
+			runlock()
+			<-writerDone
+
+			deadlocks := log.of(concurrent.LockDeadlock)
+			if len(deadlocks) != 1 || len(deadlocks[0].Cycle) != 2 {
+				t.Fatalf("expected one two-goroutine cycle, got %+v", deadlocks)
+			}
+			if c := deadlocks[0].Cycle; c[0].Mode != concurrent.LockRead || c[1].Mode != concurrent.LockWrite {
+				t.Fatalf("unexpected cycle %+v", c)
+			}
+		})
+	}
+}
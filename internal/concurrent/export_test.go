@@ -0,0 +1,32 @@
+package concurrent
+
+// DenseShardedKeyedLocker is a benchmark stand-in for ShardedKeyedLocker with its shard locks packed
+// densely, the layout paddedRWMutex is measured against. It locks the way the real locker does without
+// diagnostics.
+type DenseShardedKeyedLocker[K comparable] struct {
+	shards []rwLock
+	mask   uint64
+	hash   func(K) uint64
+}
+
+func NewDenseShardedKeyedLocker[K comparable](exp uint, hash func(K) uint64) *DenseShardedKeyedLocker[K] {
+	return &DenseShardedKeyedLocker[K]{
+		shards: make([]rwLock, 1<<exp),
+		mask:   1<<exp - 1,
+		hash:   hash,
+	}
+}
+
+func (k *DenseShardedKeyedLocker[K]) lock(key K, mode LockMode) func() {
+	lock, _, unlock := rwFuncs(&k.shards[k.hash(key)&k.mask], mode)
+	lock()
+	return unlock
+}
+
+func (k *DenseShardedKeyedLocker[K]) Lock(key K) func() {
+	return k.lock(key, LockWrite)
+}
+
+func (k *DenseShardedKeyedLocker[K]) RLock(key K) func() {
+	return k.lock(key, LockRead)
+}
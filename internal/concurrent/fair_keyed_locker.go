@@ -0,0 +1,213 @@
+package concurrent
+
+import (
+	"sync"
+
+	"github.com/dsx137/gg-kit/internal/structure"
+)
+
+type fairWaiter struct {
+	seq   uint64
+	ready chan struct{}
+}
+
+type fairEntry struct {
+	readers int
+	writer  bool
+	reads   *structure.Queue[fairWaiter]
+	writes  *structure.Queue[fairWaiter]
+}
+
+func (e *fairEntry) queued() bool {
+	return e.reads.Len() > 0 || e.writes.Len() > 0
+}
+
+func (e *fairEntry) idle() bool {
+	return e.readers == 0 && !e.writer && !e.queued()
+}
+
+// FairKeyedLocker grants the locks of a key in the order they were asked for: a reader never overtakes a
+// waiting writer and vice versa, and consecutive readers at the head of the queue share the key.
+type FairKeyedLocker[K comparable] struct {
+	mu            *sync.Mutex
+	locks         map[K]*fairEntry
+	seq           uint64
+	preferWriters bool
+}
+
+func NewFairKeyedLocker[K comparable]() *FairKeyedLocker[K] {
+	return &FairKeyedLocker[K]{
+		mu:    &sync.Mutex{},
+		locks: make(map[K]*fairEntry),
+	}
+}
+
+// NewWriterPreferringKeyedLocker keeps FIFO order among writers and among readers, but lets every waiting
+// writer go before any waiting reader.
+func NewWriterPreferringKeyedLocker[K comparable]() *FairKeyedLocker[K] {
+	k := NewFairKeyedLocker[K]()
+	k.preferWriters = true
+	return k
+}
+
+func (k *FairKeyedLocker[K]) entryLocked(key K) *fairEntry {
+	e, ok := k.locks[key]
+	if !ok {
+		e = &fairEntry{
+			reads:  structure.NewQueue[fairWaiter](),
+			writes: structure.NewQueue[fairWaiter](),
+		}
+		k.locks[key] = e
+	}
+	return e
+}
+
+// tryLocked grants the lock right away if nobody is queued and the holders allow it.
+func (k *FairKeyedLocker[K]) tryLocked(e *fairEntry, mode LockMode) bool {
+	if e.writer || e.queued() {
+		return false
+	}
+	if mode == LockRead {
+		e.readers++
+		return true
+	}
+	if e.readers > 0 {
+		return false
+	}
+	e.writer = true
+	return true
+}
+
+// grantLocked hands the key to the head of the queue for as long as the holders allow it.
+func (k *FairKeyedLocker[K]) grantLocked(e *fairEntry) {
+	for !e.writer {
+		r, rok := e.reads.Peek()
+		w, wok := e.writes.Peek()
+		switch {
+		case wok && (!rok || k.preferWriters || w.seq < r.seq):
+			if e.readers > 0 {
+				return
+			}
+			e.writes.Dequeue()
+			e.writer = true
+			close(w.ready)
+		case rok:
+			e.reads.Dequeue()
+			e.readers++
+			close(r.ready)
+		default:
+			return
+		}
+	}
+}
+
+func (k *FairKeyedLocker[K]) lock(key K, mode LockMode) func() {
+	k.mu.Lock()
+	e := k.entryLocked(key)
+	if k.tryLocked(e, mode) {
+		k.mu.Unlock()
+		return k.unlocker(key, e, mode)
+	}
+
+	k.seq++
+	w := fairWaiter{seq: k.seq, ready: make(chan struct{})}
+	if mode == LockRead {
+		e.reads.Enqueue(w)
+	} else {
+		e.writes.Enqueue(w)
+	}
+	k.mu.Unlock()
+
+	<-w.ready
+	return k.unlocker(key, e, mode)
+}
+
+func (k *FairKeyedLocker[K]) tryLock(key K, mode LockMode) (func(), bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e := k.entryLocked(key)
+	if !k.tryLocked(e, mode) {
+		if e.idle() {
+			delete(k.locks, key)
+		}
+		return nil, false
+	}
+	return k.unlocker(key, e, mode), true
+}
+
+func (k *FairKeyedLocker[K]) unlocker(key K, e *fairEntry, mode LockMode) func() {
+	return func() { k.unlock(key, e, mode) }
+}
+
+func (k *FairKeyedLocker[K]) unlock(key K, e *fairEntry, mode LockMode) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if mode == LockRead {
+		if e.readers == 0 {
+			panic("runlock of unlocked key")
+		}
+		e.readers--
+	} else {
+		if !e.writer {
+			panic("unlock of unlocked key")
+		}
+		e.writer = false
+	}
+
+	k.grantLocked(e)
+	if e.idle() {
+		delete(k.locks, key)
+	}
+}
+
+func (k *FairKeyedLocker[K]) Lock(key K) func() {
+	return k.lock(key, LockWrite)
+}
+
+func (k *FairKeyedLocker[K]) TryLock(key K) (func(), bool) {
+	return k.tryLock(key, LockWrite)
+}
+
+func (k *FairKeyedLocker[K]) RLock(key K) func() {
+	return k.lock(key, LockRead)
+}
+
+func (k *FairKeyedLocker[K]) TryRLock(key K) (func(), bool) {
+	return k.tryLock(key, LockRead)
+}
+
+func (k *FairKeyedLocker[K]) Locker(key K) sync.Locker {
+	return fairLocker[K]{k: k, key: key, mode: LockWrite}
+}
+
+func (k *FairKeyedLocker[K]) RLocker(key K) sync.Locker {
+	return fairLocker[K]{k: k, key: key, mode: LockRead}
+}
+
+// Len returns the number of keys currently held or waited on.
+func (k *FairKeyedLocker[K]) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.locks)
+}
+
+type fairLocker[K comparable] struct {
+	k    *FairKeyedLocker[K]
+	key  K
+	mode LockMode
+}
+
+func (l fairLocker[K]) Lock() {
+	l.k.lock(l.key, l.mode)
+}
+
+func (l fairLocker[K]) Unlock() {
+	l.k.mu.Lock()
+	e, ok := l.k.locks[l.key]
+	l.k.mu.Unlock()
+	if !ok {
+		panic("unlock of unlocked key")
+	}
+	l.k.unlock(l.key, e, l.mode)
+}
@@ -0,0 +1,120 @@
+package concurrent_test
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+	"github.com/dsx137/gg-kit/internal/concurrent/lockertest"
+)
+
+func TestFairKeyedLocker(t *testing.T) {
+	t.Run("fifo", func(t *testing.T) {
+		lockertest.Run(t, func() concurrent.KeyedLocker[string] {
+			return concurrent.NewFairKeyedLocker[string]()
+		})
+	})
+	t.Run("writer preferring", func(t *testing.T) {
+		lockertest.Run(t, func() concurrent.KeyedLocker[string] {
+			return concurrent.NewWriterPreferringKeyedLocker[string]()
+		})
+	})
+}
+
+func TestFairKeyedLockerOrder(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		l    *concurrent.FairKeyedLocker[string]
+		want []string
+	}{
+		{"fifo", concurrent.NewFairKeyedLocker[string](), []string{"r1", "w2", "w3"}},
+		{"writer preferring", concurrent.NewWriterPreferringKeyedLocker[string](), []string{"w2", "w3", "r1"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var order []string
+			wg := &sync.WaitGroup{}
+
+			unlock := tt.l.Lock("k")
+			for _, name := range []string{"r1", "w2", "w3"} {
+				lock := tt.l.Lock
+				if name[0] == 'r' {
+					lock = tt.l.RLock
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					unlock := lock("k")
+					mu.Lock()
+					order = append(order, name)
+					mu.Unlock()
+					unlock()
+				}()
+				// Give each goroutine time to queue up before the next one asks.
+				time.Sleep(10 * time.Millisecond)
+			}
+			unlock()
+			wg.Wait()
+
+			if !slices.Equal(order, tt.want) {
+				t.Fatalf("expected locks in order %v, got %v", tt.want, order)
+			}
+			if n := tt.l.Len(); n != 0 {
+				t.Fatalf("expected no entries after all unlocks, got %d", n)
+			}
+		})
+	}
+}
+
+// BenchmarkWriterLatency measures how long a writer waits for a key that a stream of readers keeps
+// read-locked, reporting the 50th and 99th percentiles.
+func BenchmarkWriterLatency(b *testing.B) {
+	lockers := []struct {
+		name string
+		l    concurrent.KeyedLocker[string]
+	}{
+		{"map", concurrent.NewMapKeyedLocker[string]()},
+		{"fair", concurrent.NewFairKeyedLocker[string]()},
+		{"writer preferring", concurrent.NewWriterPreferringKeyedLocker[string]()},
+	}
+	for _, lc := range lockers {
+		b.Run(lc.name, func(b *testing.B) {
+			stop := make(chan struct{})
+			wg := &sync.WaitGroup{}
+			for range 8 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						select {
+						case <-stop:
+							return
+						default:
+						}
+						runlock := lc.l.RLock("hot")
+						time.Sleep(10 * time.Microsecond)
+						runlock()
+					}
+				}()
+			}
+
+			waits := make([]time.Duration, 0, b.N)
+			b.ResetTimer()
+			for range b.N {
+				start := time.Now()
+				unlock := lc.l.Lock("hot")
+				waits = append(waits, time.Since(start))
+				unlock()
+			}
+			b.StopTimer()
+			close(stop)
+			wg.Wait()
+
+			slices.Sort(waits)
+			b.ReportMetric(float64(waits[len(waits)*50/100]), "p50-ns")
+			b.ReportMetric(float64(waits[len(waits)*99/100]), "p99-ns")
+		})
+	}
+}
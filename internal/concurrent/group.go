@@ -0,0 +1,112 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+)
+
+type groupCall[V any] struct {
+	done     chan struct{}
+	v        V
+	err      error
+	panicked bool
+	p        any
+	// callers counts the callers still waiting; cancel stops fn once it drops to zero.
+	callers int
+	cancel  context.CancelFunc
+}
+
+// Group collapses concurrent calls for the same key into one execution whose result every caller gets.
+type Group[K comparable, V any] struct {
+	mu    *sync.Mutex
+	calls map[K]*groupCall[V]
+}
+
+func NewGroup[K comparable, V any]() *Group[K, V] {
+	return &Group[K, V]{
+		mu:    &sync.Mutex{},
+		calls: make(map[K]*groupCall[V]),
+	}
+}
+
+// Do runs fn for key unless a call for key is already in flight, in which case it waits for that call's
+// result instead; shared reports whether the result went to more than one caller. fn runs on its own
+// goroutine with the first caller's context values but none of its cancellation, so a caller whose ctx is
+// done returns ctx.Err() on its own while the call carries on for the others. Once every caller has given
+// up, fn's ctx is cancelled and the next Do for key starts a new call. A panic in fn is re-raised in every
+// caller still waiting.
+func (g *Group[K, V]) Do(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (v V, err error, shared bool) {
+	if err := ctx.Err(); err != nil {
+		return v, err, false
+	}
+
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if ok {
+		c.callers++
+	} else {
+		fnCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &groupCall[V]{done: make(chan struct{}), callers: 1, cancel: cancel}
+		g.calls[key] = c
+		go g.run(fnCtx, key, c, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		if g.leave(key, c) {
+			return v, ctx.Err(), false
+		}
+	}
+	if c.panicked {
+		panic(c.p)
+	}
+	// callers is final once done is closed: it counts exactly the callers getting the result.
+	return c.v, c.err, c.callers > 1
+}
+
+// leave takes a cancelled caller off c and reports whether it left before c finished; if c finished first,
+// the caller takes the result after all.
+func (g *Group[K, V]) leave(key K, c *groupCall[V]) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	c.callers--
+	if c.callers == 0 {
+		c.cancel()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+	}
+	return true
+}
+
+func (g *Group[K, V]) run(ctx context.Context, key K, c *groupCall[V], fn func(ctx context.Context) (V, error)) {
+	defer func() {
+		if p := recover(); p != nil {
+			c.panicked, c.p = true, p
+		}
+		c.cancel()
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+		}
+		// Closed under mu, so leave sees either a finished call or one it can still leave.
+		close(c.done)
+	}()
+	c.v, c.err = fn(ctx)
+}
+
+// Forget makes the next Do for key start a new call even if one is still in flight. Callers already waiting
+// keep waiting for the old one.
+func (g *Group[K, V]) Forget(key K) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.calls, key)
+}
@@ -0,0 +1,151 @@
+package concurrent_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+)
+
+func TestGroupDeduplicates(t *testing.T) {
+	g := concurrent.NewGroup[string, int]()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const callers = 10
+	wg := &sync.WaitGroup{}
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err, shared := g.Do(context.Background(), "k", fn)
+			if v != 42 || err != nil || !shared {
+				t.Errorf("expected a shared 42, got %d, %v, shared %v", v, err, shared)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("expected one execution, got %d", n)
+	}
+
+	v, _, shared := g.Do(context.Background(), "k", func(context.Context) (int, error) { return 7, nil })
+	if v != 7 || shared {
+		t.Fatalf("expected a fresh unshared call after the first finished, got %d, shared %v", v, shared)
+	}
+}
+
+func TestGroupCallerCancel(t *testing.T) {
+	g := concurrent.NewGroup[string, int]()
+	release := make(chan struct{})
+	fnErr := make(chan error, 1)
+	fn := func(ctx context.Context) (int, error) {
+		<-release
+		fnErr <- ctx.Err()
+		return 1, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err, _ := g.Do(ctx, "k", fn)
+		first <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	second := make(chan int)
+	go func() {
+		v, err, shared := g.Do(context.Background(), "k", fn)
+		if err != nil || shared {
+			t.Errorf("expected an unshared result for the only caller left, got %v, shared %v", err, shared)
+		}
+		second <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the first caller to see its cancellation, got %v", err)
+	}
+	close(release)
+	if v := <-second; v != 1 {
+		t.Fatalf("expected the second caller to get the result, got %d", v)
+	}
+	if err := <-fnErr; err != nil {
+		t.Fatalf("a caller's cancellation reached the shared call: %v", err)
+	}
+}
+
+func TestGroupAllCallersCancel(t *testing.T) {
+	g := concurrent.NewGroup[string, int]()
+	fnErr := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err, _ := g.Do(ctx, "k", func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			fnErr <- ctx.Err()
+			return 0, ctx.Err()
+		})
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the caller to see its cancellation, got %v", err)
+	}
+	select {
+	case err := <-fnErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected fn's ctx to be cancelled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fn kept running after every caller gave up")
+	}
+	v, _, _ := g.Do(context.Background(), "k", func(context.Context) (int, error) { return 2, nil })
+	if v != 2 {
+		t.Fatalf("expected a new call after the abandoned one, got %d", v)
+	}
+}
+
+func TestGroupForget(t *testing.T) {
+	g := concurrent.NewGroup[string, int]()
+	release := make(chan struct{})
+	done := make(chan int)
+	go func() {
+		v, _, _ := g.Do(context.Background(), "k", func(context.Context) (int, error) {
+			<-release
+			return 1, nil
+		})
+		done <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	g.Forget("k")
+	v, _, _ := g.Do(context.Background(), "k", func(context.Context) (int, error) { return 2, nil })
+	if v != 2 {
+		t.Fatalf("expected a new call after Forget, got %d", v)
+	}
+	close(release)
+	if v := <-done; v != 1 {
+		t.Fatalf("expected the forgotten call to finish for its caller, got %d", v)
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	g := concurrent.NewGroup[string, int]()
+	expectPanic(t, "a panicking call", func() {
+		g.Do(context.Background(), "k", func(context.Context) (int, error) { panic("boom") })
+	})
+}
@@ -0,0 +1,59 @@
+package concurrent
+
+import "hash/maphash"
+
+type integer interface {
+	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | uintptr
+}
+
+// NewHasher returns a hash function for K seeded with a fresh random seed, so two hashers never agree on
+// which keys collide. Strings and the built-in integer types take a fast path; every other key, including
+// named string and integer types, goes through maphash.Comparable.
+func NewHasher[K comparable]() func(K) uint64 {
+	seed := maphash.MakeSeed()
+	var h any
+	switch any(*new(K)).(type) {
+	case string:
+		h = func(s string) uint64 { return maphash.String(seed, s) }
+	case int:
+		h = intHasher[int](seed)
+	case int8:
+		h = intHasher[int8](seed)
+	case int16:
+		h = intHasher[int16](seed)
+	case int32:
+		h = intHasher[int32](seed)
+	case int64:
+		h = intHasher[int64](seed)
+	case uint:
+		h = intHasher[uint](seed)
+	case uint8:
+		h = intHasher[uint8](seed)
+	case uint16:
+		h = intHasher[uint16](seed)
+	case uint32:
+		h = intHasher[uint32](seed)
+	case uint64:
+		h = intHasher[uint64](seed)
+	case uintptr:
+		h = intHasher[uintptr](seed)
+	default:
+		return func(key K) uint64 { return maphash.Comparable(seed, key) }
+	}
+	return h.(func(K) uint64)
+}
+
+// intHasher mixes the seeded value with the splitmix64 finalizer, which spreads consecutive integers over
+// the low bits that pick a shard.
+func intHasher[I integer](seed maphash.Seed) func(I) uint64 {
+	s := maphash.String(seed, "")
+	return func(v I) uint64 {
+		x := uint64(v) ^ s
+		x ^= x >> 30
+		x *= 0xbf58476d1ce4e5b9
+		x ^= x >> 27
+		x *= 0x94d049bb133111eb
+		x ^= x >> 31
+		return x
+	}
+}
@@ -0,0 +1,75 @@
+package concurrent_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+	"github.com/dsx137/gg-kit/internal/concurrent/lockertest"
+)
+
+type pair struct {
+	a, b int
+}
+
+type name string
+
+func TestDefaultShardedKeyedLocker(t *testing.T) {
+	lockertest.Run(t, func() concurrent.KeyedLocker[string] {
+		return concurrent.NewDefaultShardedKeyedLocker[string](4)
+	})
+}
+
+func TestHasherDistribution(t *testing.T) {
+	t.Run("string", func(t *testing.T) { checkDistribution(t, concurrent.NewHasher[string](), strconv.Itoa) })
+	t.Run("int", func(t *testing.T) { checkDistribution(t, concurrent.NewHasher[int](), func(i int) int { return i }) })
+	t.Run("uint16", func(t *testing.T) {
+		checkDistribution(t, concurrent.NewHasher[uint16](), func(i int) uint16 { return uint16(i) })
+	})
+	t.Run("struct", func(t *testing.T) {
+		checkDistribution(t, concurrent.NewHasher[pair](), func(i int) pair { return pair{i / 7, i % 7} })
+	})
+	t.Run("named", func(t *testing.T) {
+		checkDistribution(t, concurrent.NewHasher[name](), func(i int) name { return name(strconv.Itoa(i)) })
+	})
+}
+
+func TestHasherSeeds(t *testing.T) {
+	a, b := concurrent.NewHasher[string](), concurrent.NewHasher[string]()
+	if a("key") != a("key") {
+		t.Fatal("a hasher must be deterministic")
+	}
+	same := 0
+	for i := range 64 {
+		if a(strconv.Itoa(i)) == b(strconv.Itoa(i)) {
+			same++
+		}
+	}
+	if same == 64 {
+		t.Fatal("two hashers share a seed")
+	}
+}
+
+func checkDistribution[K comparable](t *testing.T, h func(K) uint64, key func(int) K) {
+	t.Helper()
+	for exp := uint(1); exp <= 6; exp++ {
+		checkShards(t, exp, 1024<<exp, func(i int) uint64 { return h(key(i)) })
+	}
+}
+
+// checkShards hashes n consecutive keys onto 1<<exp shards and requires every shard to get within 30% of
+// its fair share.
+func checkShards(t *testing.T, exp uint, n int, hash func(int) uint64) {
+	t.Helper()
+	shards := 1 << exp
+	counts := make([]int, shards)
+	for i := range n {
+		counts[hash(i)&uint64(shards-1)]++
+	}
+	want := n / shards
+	for i, c := range counts {
+		if c < want*7/10 || c > want*13/10 {
+			t.Fatalf("%d shards: shard %d got %d keys, want about %d", shards, i, c, want)
+		}
+	}
+}
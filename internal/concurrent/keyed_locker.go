@@ -4,9 +4,25 @@ import "sync"
 
 type KeyedLocker[K comparable] interface {
 	Lock(key K) func()
-	TryLock(key K) func()
+	TryLock(key K) (func(), bool)
 	RLock(key K) func()
-	TryRLock(key K) func()
+	TryRLock(key K) (func(), bool)
 	Locker(key K) sync.Locker
 	RLocker(key K) sync.Locker
 }
+
+var (
+	_ KeyedLocker[int] = (*MapKeyedLocker[int])(nil)
+	_ KeyedLocker[int] = (*ShardedKeyedLocker[int])(nil)
+	_ KeyedLocker[int] = (*ReentrantKeyedLocker[int])(nil)
+	_ KeyedLocker[int] = (*FairKeyedLocker[int])(nil)
+
+	_ KeyedLocker[string] = (*PathLocker)(nil)
+)
+
+func rwFuncs(mu *rwLock, mode LockMode) (lock func(), tryLock func() bool, unlock func()) {
+	if mode == LockRead {
+		return mu.RLock, mu.TryRLock, mu.RUnlock
+	}
+	return mu.Lock, mu.TryLock, mu.Unlock
+}
@@ -0,0 +1,182 @@
+package concurrent_test
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+	"github.com/dsx137/gg-kit/internal/concurrent/lockertest"
+)
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func TestMapKeyedLocker(t *testing.T) {
+	lockertest.Run(t, func() concurrent.KeyedLocker[string] {
+		return concurrent.NewMapKeyedLocker[string]()
+	})
+}
+
+func TestShardedKeyedLocker(t *testing.T) {
+	lockertest.Run(t, func() concurrent.KeyedLocker[string] {
+		return concurrent.NewShardedKeyedLocker(4, hashString)
+	})
+}
+
+func TestInstrumentedKeyedLockers(t *testing.T) {
+	d := concurrent.NewLockDiagnostics[string](0, nil)
+	t.Run("map", func(t *testing.T) {
+		lockertest.Run(t, func() concurrent.KeyedLocker[string] {
+			return concurrent.NewMapKeyedLocker[string]().Instrument(d)
+		})
+	})
+	t.Run("sharded", func(t *testing.T) {
+		lockertest.Run(t, func() concurrent.KeyedLocker[string] {
+			return concurrent.NewShardedKeyedLocker(4, hashString).Instrument(d)
+		})
+	})
+	if s := d.Dump(); len(s.Holders) != 0 || len(s.Waiters) != 0 {
+		t.Fatalf("expected no holders or waiters left, got %+v", s)
+	}
+}
+
+type ctxLocker interface {
+	concurrent.KeyedLocker[string]
+	LockCtx(ctx context.Context, key string) (func(), error)
+	RLockCtx(ctx context.Context, key string) (func(), error)
+}
+
+func TestLockCtx(t *testing.T) {
+	lockers := map[string]ctxLocker{
+		"map":     concurrent.NewMapKeyedLocker[string](),
+		"sharded": concurrent.NewShardedKeyedLocker(4, hashString),
+	}
+	for name, l := range lockers {
+		t.Run(name, func(t *testing.T) {
+			unlock := l.Lock("k")
+			for _, lockCtx := range []func(context.Context, string) (func(), error){l.LockCtx, l.RLockCtx} {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				if _, err := lockCtx(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+					t.Fatalf("expected deadline exceeded, got %v", err)
+				}
+				cancel()
+			}
+			unlock()
+
+			// Abandoned attempts must not leave the key locked.
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			unlock, err := l.LockCtx(ctx, "k")
+			if err != nil {
+				t.Fatalf("expected the key to be free again, got %v", err)
+			}
+			unlock()
+			runlock, err := l.RLockCtx(ctx, "k")
+			if err != nil {
+				t.Fatalf("expected the key to be free again, got %v", err)
+			}
+			runlock()
+		})
+	}
+}
+
+func TestLockCtxAbandonedWriterLetsReadersIn(t *testing.T) {
+	lockers := map[string]ctxLocker{
+		"map":     concurrent.NewMapKeyedLocker[string](),
+		"sharded": concurrent.NewShardedKeyedLocker(4, hashString),
+	}
+	for name, l := range lockers {
+		t.Run(name, func(t *testing.T) {
+			runlock := l.RLock("k")
+			defer runlock()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if _, err := l.LockCtx(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected deadline exceeded, got %v", err)
+			}
+
+			// The writer gave up, so it must not keep new readers out while the first one still holds the key.
+			done := make(chan struct{})
+			go func() {
+				l.RLock("k")()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("expected a new reader to get in after LockCtx gave up")
+			}
+		})
+	}
+}
+
+type manyLocker interface {
+	LockMany(keys ...string) func()
+	RLockMany(keys ...string) func()
+}
+
+func TestLockMany(t *testing.T) {
+	lockers := map[string]manyLocker{
+		"map": concurrent.NewMapKeyedLocker[string](),
+		// Two shards make keys collide constantly.
+		"sharded": concurrent.NewShardedKeyedLocker(1, hashString),
+	}
+	accounts := []string{"a", "b", "c", "d", "e"}
+
+	for name, l := range lockers {
+		t.Run(name, func(t *testing.T) {
+			balances := make(map[string]*int, len(accounts))
+			for _, a := range accounts {
+				balances[a] = new(int)
+				*balances[a] = 100
+			}
+
+			wg := &sync.WaitGroup{}
+			for g := 0; g < 16; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < 500; i++ {
+						from, to := accounts[(g+i)%len(accounts)], accounts[(g*7+i*3)%len(accounts)]
+						if i%5 == 0 {
+							unlock := l.RLockMany(to, from, to)
+							_ = *balances[from] + *balances[to]
+							unlock()
+							continue
+						}
+						unlock := l.LockMany(from, to, from)
+						*balances[from]--
+						*balances[to]++
+						unlock()
+					}
+				}()
+			}
+
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("LockMany deadlocked")
+			}
+
+			total := 0
+			for _, b := range balances {
+				total += *b
+			}
+			if total != 100*len(accounts) {
+				t.Fatalf("expected total %d, got %d", 100*len(accounts), total)
+			}
+		})
+	}
+}
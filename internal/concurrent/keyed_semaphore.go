@@ -0,0 +1,206 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dsx137/gg-kit/internal/generic"
+)
+
+type semWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+type semEntry struct {
+	cur     int64
+	waiters *generic.List[semWaiter]
+}
+
+// KeyedSemaphore lets up to a limit of weighted units per key be held at once. Waiters of a key are served
+// in FIFO order, so a heavy waiter is not starved by a stream of light ones. Keys nobody holds or waits
+// for are forgotten, except for their limit overrides.
+type KeyedSemaphore[K comparable] struct {
+	mu     *sync.Mutex
+	size   int64
+	limits map[K]int64
+	sems   map[K]*semEntry
+}
+
+func NewKeyedSemaphore[K comparable](size int64) *KeyedSemaphore[K] {
+	if size < 1 {
+		panic("size must be positive")
+	}
+	return &KeyedSemaphore[K]{
+		mu:     &sync.Mutex{},
+		size:   size,
+		limits: make(map[K]int64),
+		sems:   make(map[K]*semEntry),
+	}
+}
+
+// SetLimit overrides the limit of key; a limit of 0 restores the default. Lowering a limit below what is
+// currently held makes new acquisitions wait until enough is released, and leaves waiters heavier than
+// the new limit waiting until it is raised again or their ctx is done.
+func (s *KeyedSemaphore[K]) SetLimit(key K, limit int64) {
+	if limit < 0 {
+		panic("limit must not be negative")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if limit == 0 {
+		delete(s.limits, key)
+	} else {
+		s.limits[key] = limit
+	}
+	if e, ok := s.sems[key]; ok {
+		s.notifyLocked(key, e)
+	}
+}
+
+// Limit returns the number of units key allows.
+func (s *KeyedSemaphore[K]) Limit(key K) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limitLocked(key)
+}
+
+func (s *KeyedSemaphore[K]) limitLocked(key K) int64 {
+	if limit, ok := s.limits[key]; ok {
+		return limit
+	}
+	return s.size
+}
+
+func (s *KeyedSemaphore[K]) entryLocked(key K) *semEntry {
+	e, ok := s.sems[key]
+	if !ok {
+		e = &semEntry{waiters: generic.NewList[semWaiter]()}
+		s.sems[key] = e
+	}
+	return e
+}
+
+func (s *KeyedSemaphore[K]) evictLocked(key K, e *semEntry) {
+	if e.cur == 0 && e.waiters.Len() == 0 {
+		delete(s.sems, key)
+	}
+}
+
+// notifyLocked admits waiters from the front of the queue for as long as they fit.
+func (s *KeyedSemaphore[K]) notifyLocked(key K, e *semEntry) {
+	limit := s.limitLocked(key)
+	for {
+		front := e.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value()
+		if e.cur+w.n > limit {
+			return
+		}
+		e.cur += w.n
+		e.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// Acquire waits until weight units of key are free and takes them, or gives up with ctx.Err() once ctx is
+// done. Since limits can change, a weight above the key's current limit is not an error: it waits, holding
+// up the waiters behind it, until the limit is raised or ctx is done.
+func (s *KeyedSemaphore[K]) Acquire(ctx context.Context, key K, weight int64) (func(), error) {
+	if weight < 1 {
+		panic("weight must be positive")
+	}
+	s.mu.Lock()
+	e := s.entryLocked(key)
+	if e.waiters.Len() == 0 && e.cur+weight <= s.limitLocked(key) {
+		e.cur += weight
+		s.mu.Unlock()
+		return s.releaser(key, e, weight), nil
+	}
+	if err := ctx.Err(); err != nil {
+		s.evictLocked(key, e)
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	w := semWaiter{n: weight, ready: make(chan struct{})}
+	elem := e.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.releaser(key, e, weight), nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-w.ready:
+		// Admitted while giving up: hand the units back.
+		e.cur -= weight
+	default:
+		front := elem.Prev() == nil
+		e.waiters.Remove(elem)
+		if !front {
+			s.evictLocked(key, e)
+			return nil, ctx.Err()
+		}
+	}
+	// The queue may have been held up behind this waiter.
+	s.notifyLocked(key, e)
+	s.evictLocked(key, e)
+	return nil, ctx.Err()
+}
+
+// TryAcquire takes weight units of key if they are free and nobody is waiting for the key. A weight above
+// the key's limit never fits.
+func (s *KeyedSemaphore[K]) TryAcquire(key K, weight int64) (func(), bool) {
+	if weight < 1 {
+		panic("weight must be positive")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entryLocked(key)
+	if e.waiters.Len() > 0 || e.cur+weight > s.limitLocked(key) {
+		s.evictLocked(key, e)
+		return nil, false
+	}
+	e.cur += weight
+	return s.releaser(key, e, weight), true
+}
+
+// releaser returns a function giving the units back. Calling it more than once panics.
+func (s *KeyedSemaphore[K]) releaser(key K, e *semEntry, weight int64) func() {
+	released := false
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if released {
+			panic("release of released units")
+		}
+		released = true
+		e.cur -= weight
+		s.notifyLocked(key, e)
+		s.evictLocked(key, e)
+	}
+}
+
+// Held returns the number of units of key currently held.
+func (s *KeyedSemaphore[K]) Held(key K) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.sems[key]; ok {
+		return e.cur
+	}
+	return 0
+}
+
+// Len returns the number of keys currently held or waited on.
+func (s *KeyedSemaphore[K]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sems)
+}
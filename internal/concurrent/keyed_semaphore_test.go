@@ -0,0 +1,176 @@
+package concurrent_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+)
+
+func TestKeyedSemaphoreLimits(t *testing.T) {
+	s := concurrent.NewKeyedSemaphore[string](3)
+	ctx := context.Background()
+
+	r1, err := s.Acquire(ctx, "a", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := s.Acquire(ctx, "a", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.TryAcquire("a", 1); ok {
+		t.Fatal("TryAcquire exceeded the limit")
+	}
+	r3, ok := s.TryAcquire("b", 3)
+	if !ok {
+		t.Fatal("keys must not share units")
+	}
+
+	s.SetLimit("a", 4)
+	r4, ok := s.TryAcquire("a", 1)
+	if !ok {
+		t.Fatal("TryAcquire did not see the raised limit")
+	}
+	if held := s.Held("a"); held != 4 {
+		t.Fatalf("expected 4 units of a held, got %d", held)
+	}
+
+	for _, release := range []func(){r1, r2, r3, r4} {
+		release()
+	}
+	if n := s.Len(); n != 0 {
+		t.Fatalf("expected no entries after all releases, got %d", n)
+	}
+	if limit := s.Limit("a"); limit != 4 {
+		t.Fatalf("expected the override to outlive the entry, got limit %d", limit)
+	}
+	s.SetLimit("a", 0)
+	if limit := s.Limit("a"); limit != 3 {
+		t.Fatalf("expected the default limit back, got %d", limit)
+	}
+
+	expectPanic(t, "a zero weight", func() { s.TryAcquire("a", 0) })
+	expectPanic(t, "a zero weight", func() { s.Acquire(ctx, "a", 0) })
+	if _, ok := s.TryAcquire("a", 3); !ok {
+		t.Fatal("a panicking Acquire left the semaphore locked")
+	}
+}
+
+func TestKeyedSemaphoreWeightAboveLimit(t *testing.T) {
+	s := concurrent.NewKeyedSemaphore[string](3)
+	if _, ok := s.TryAcquire("k", 4); ok {
+		t.Fatal("TryAcquire took more units than the limit")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(ctx, "k", 4); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the heavy Acquire to wait until its deadline, got %v", err)
+	}
+
+	heavy := make(chan func())
+	go func() {
+		release, err := s.Acquire(context.Background(), "k", 4)
+		if err != nil {
+			t.Error(err)
+		}
+		heavy <- release
+	}()
+	for s.Len() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	s.SetLimit("k", 4)
+	select {
+	case release := <-heavy:
+		release()
+	case <-time.After(5 * time.Second):
+		t.Fatal("the heavy waiter was not admitted after the limit was raised")
+	}
+	if n := s.Len(); n != 0 {
+		t.Fatalf("expected no entries after all releases, got %d", n)
+	}
+}
+
+func TestKeyedSemaphoreBoundsHolders(t *testing.T) {
+	s := concurrent.NewKeyedSemaphore[string](2)
+	s.SetLimit("wide", 5)
+	limits := map[string]int64{"narrow": 2, "wide": 5}
+
+	current := map[string]*atomic.Int64{"narrow": {}, "wide": {}}
+	wg := &sync.WaitGroup{}
+	for g := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := "narrow"
+			if g%2 == 0 {
+				key = "wide"
+			}
+			for range 50 {
+				release, err := s.Acquire(context.Background(), key, 1)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if n := current[key].Add(1); n > limits[key] {
+					t.Errorf("%d holders of %s, limit %d", n, key, limits[key])
+				}
+				time.Sleep(10 * time.Microsecond)
+				current[key].Add(-1)
+				release()
+			}
+		}()
+	}
+	wg.Wait()
+	if n := s.Len(); n != 0 {
+		t.Fatalf("expected no entries after all releases, got %d", n)
+	}
+}
+
+func TestKeyedSemaphoreCancel(t *testing.T) {
+	s := concurrent.NewKeyedSemaphore[string](2)
+	release, _ := s.Acquire(context.Background(), "k", 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	heavy := make(chan error)
+	go func() {
+		_, err := s.Acquire(ctx, "k", 2)
+		heavy <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// One unit is free, but the light waiter queues behind the heavy one until it gives up.
+	light := make(chan func())
+	go func() {
+		release, err := s.Acquire(context.Background(), "k", 1)
+		if err != nil {
+			t.Error(err)
+		}
+		light <- release
+	}()
+	select {
+	case <-light:
+		t.Fatal("light waiter overtook the heavy one")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if err := <-heavy; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the heavy waiter to time out, got %v", err)
+	}
+	select {
+	case release2 := <-light:
+		release2()
+	case <-time.After(5 * time.Second):
+		t.Fatal("light waiter was not admitted after the heavy one gave up")
+	}
+	release()
+	if n := s.Len(); n != 0 {
+		t.Fatalf("expected no entries after all releases, got %d", n)
+	}
+}
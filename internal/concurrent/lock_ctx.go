@@ -0,0 +1,127 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dsx137/gg-kit/internal/lang"
+)
+
+// rwLock is a sync.RWMutex whose lockCtx can give up. A goroutine blocked in RWMutex.Lock cannot be
+// interrupted, and while it waits it keeps every new reader out, so a writer that merely timed out would
+// go on stalling the key. Cancellable acquisitions therefore never block in the RWMutex: they retry its
+// TryLock whenever the lock is released, and a cancellable writer holds off new readers through pending,
+// which it drops as soon as it gives up.
+type rwLock struct {
+	rw sync.RWMutex
+	// pending counts cancellable writers; waiting counts everyone blocked outside rw, who need waking.
+	pending atomic.Int32
+	waiting atomic.Int32
+	mu      sync.Mutex
+	wake    chan struct{}
+}
+
+func (l *rwLock) Lock() {
+	l.rw.Lock()
+}
+
+func (l *rwLock) TryLock() bool {
+	return l.rw.TryLock()
+}
+
+func (l *rwLock) Unlock() {
+	l.rw.Unlock()
+	l.notify()
+}
+
+func (l *rwLock) RLock() {
+	if l.pending.Load() > 0 {
+		l.waitPending()
+	}
+	l.rw.RLock()
+}
+
+func (l *rwLock) TryRLock() bool {
+	return l.pending.Load() == 0 && l.rw.TryRLock()
+}
+
+func (l *rwLock) RUnlock() {
+	l.rw.RUnlock()
+	l.notify()
+}
+
+func (l *rwLock) RLocker() sync.Locker {
+	return (*rlocker)(l)
+}
+
+type rlocker rwLock
+
+func (r *rlocker) Lock()   { (*rwLock)(r).RLock() }
+func (r *rlocker) Unlock() { (*rwLock)(r).RUnlock() }
+
+// waitPending lets the cancellable writers go first, the way a writer blocked in rw would.
+func (l *rwLock) waitPending() {
+	l.waiting.Add(1)
+	defer l.waiting.Add(-1)
+	for {
+		wake := l.waitChan()
+		if l.pending.Load() == 0 {
+			return
+		}
+		<-wake
+	}
+}
+
+func (l *rwLock) waitChan() chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.wake == nil {
+		l.wake = make(chan struct{})
+	}
+	return l.wake
+}
+
+func (l *rwLock) notify() {
+	if l.waiting.Load() == 0 {
+		return
+	}
+	l.mu.Lock()
+	l.wake = lang.Wake(l.wake)
+	l.mu.Unlock()
+}
+
+// lockCtx locks l in mode or returns ctx.Err() once ctx is done, leaving l as if it had never been asked.
+func (l *rwLock) lockCtx(ctx context.Context, mode LockMode) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, tryLock, _ := rwFuncs(l, mode)
+	if tryLock() {
+		return nil
+	}
+
+	l.waiting.Add(1)
+	defer l.waiting.Add(-1)
+	if mode == LockWrite {
+		l.pending.Add(1)
+		// Whoever is held off by pending must look again once it drops, whether this writer got the lock
+		// or gave up.
+		defer func() {
+			l.pending.Add(-1)
+			l.notify()
+		}()
+	}
+	for {
+		// Take the wake channel before trying, so a release in between is not missed.
+		wake := l.waitChan()
+		if mode == LockWrite && l.rw.TryLock() || mode == LockRead && l.TryRLock() {
+			return nil
+		}
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package lockertest is a conformance suite for concurrent.KeyedLocker implementations.
+// Run it under the race detector.
+package lockertest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+)
+
+// Run checks the behaviour every KeyedLocker must have. Contended acquisitions are always made from
+// another goroutine, so reentrant implementations pass as well.
+func Run(t *testing.T, newLocker func() concurrent.KeyedLocker[string]) {
+	t.Run("MutualExclusion", func(t *testing.T) { testMutualExclusion(t, newLocker()) })
+	t.Run("ReadersShare", func(t *testing.T) { testReadersShare(t, newLocker()) })
+	t.Run("WriterExcludesReaders", func(t *testing.T) { testWriterExcludesReaders(t, newLocker()) })
+	t.Run("TryLock", func(t *testing.T) { testTryLock(t, newLocker()) })
+	t.Run("TryRLock", func(t *testing.T) { testTryRLock(t, newLocker()) })
+	t.Run("Lockers", func(t *testing.T) { testLockers(t, newLocker()) })
+}
+
+// TryElsewhere attempts a TryLock or TryRLock of key from another goroutine, releasing it there on success.
+func TryElsewhere(l concurrent.KeyedLocker[string], key string, write bool) bool {
+	ch := make(chan bool)
+	go func() {
+		try := l.TryRLock
+		if write {
+			try = l.TryLock
+		}
+		unlock, ok := try(key)
+		if ok {
+			unlock()
+		}
+		ch <- ok
+	}()
+	return <-ch
+}
+
+func testMutualExclusion(t *testing.T, l concurrent.KeyedLocker[string]) {
+	const goroutines, iterations = 8, 200
+	keys := []string{"a", "b", "c"}
+	counters := make(map[string]*int, len(keys))
+	for _, key := range keys {
+		counters[key] = new(int)
+	}
+
+	wg := &sync.WaitGroup{}
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				key := keys[(g+i)%len(keys)]
+				unlock := l.Lock(key)
+				// Unsynchronized read-modify-write: the race detector flags it unless Lock excludes.
+				v := *counters[key]
+				*counters[key] = v + 1
+				unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, key := range keys {
+		total += *counters[key]
+	}
+	if total != goroutines*iterations {
+		t.Fatalf("expected %d increments, got %d", goroutines*iterations, total)
+	}
+}
+
+func testReadersShare(t *testing.T, l concurrent.KeyedLocker[string]) {
+	const readers = 4
+	start := &sync.WaitGroup{}
+	start.Add(readers)
+	release := make(chan struct{})
+	done := &sync.WaitGroup{}
+	for i := 0; i < readers; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			unlock := l.RLock("k")
+			start.Done()
+			<-release
+			unlock()
+		}()
+	}
+
+	allIn := make(chan struct{})
+	go func() {
+		start.Wait()
+		close(allIn)
+	}()
+	select {
+	case <-allIn:
+	case <-time.After(5 * time.Second):
+		t.Fatal("readers of the same key did not share the lock")
+	}
+	close(release)
+	done.Wait()
+}
+
+func testWriterExcludesReaders(t *testing.T, l concurrent.KeyedLocker[string]) {
+	unlock := l.Lock("k")
+	acquired := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runlock := l.RLock("k")
+		close(acquired)
+		runlock()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("reader acquired a key held by a writer")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reader was not admitted after the writer unlocked")
+	}
+	<-done
+}
+
+func testTryLock(t *testing.T, l concurrent.KeyedLocker[string]) {
+	unlock, ok := l.TryLock("k")
+	if !ok || unlock == nil {
+		t.Fatal("TryLock on a free key failed")
+	}
+	if TryElsewhere(l, "k", true) {
+		t.Fatal("TryLock succeeded on a write-locked key")
+	}
+	if TryElsewhere(l, "k", false) {
+		t.Fatal("TryRLock succeeded on a write-locked key")
+	}
+	unlock()
+
+	if !TryElsewhere(l, "k", true) {
+		t.Fatal("TryLock failed after unlock")
+	}
+}
+
+func testTryRLock(t *testing.T, l concurrent.KeyedLocker[string]) {
+	runlock, ok := l.TryRLock("k")
+	if !ok || runlock == nil {
+		t.Fatal("TryRLock on a free key failed")
+	}
+	if TryElsewhere(l, "k", true) {
+		t.Fatal("TryLock succeeded on a read-locked key")
+	}
+	if !TryElsewhere(l, "k", false) {
+		t.Fatal("TryRLock did not share a read-locked key")
+	}
+	runlock()
+
+	if !TryElsewhere(l, "k", true) {
+		t.Fatal("TryLock failed after the readers left")
+	}
+}
+
+func testLockers(t *testing.T, l concurrent.KeyedLocker[string]) {
+	locker := l.Locker("k")
+	locker.Lock()
+	if TryElsewhere(l, "k", false) {
+		t.Fatal("Locker did not lock the key")
+	}
+	locker.Unlock()
+
+	rlocker := l.RLocker("k")
+	rlocker.Lock()
+	if TryElsewhere(l, "k", true) {
+		t.Fatal("RLocker did not read-lock the key")
+	}
+	if !TryElsewhere(l, "k", false) {
+		t.Fatal("RLocker excluded other readers")
+	}
+	rlocker.Unlock()
+}
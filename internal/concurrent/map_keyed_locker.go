@@ -1,59 +1,304 @@
 package concurrent
 
 import (
+	"cmp"
+	"context"
+	"slices"
 	"sync"
-
-	"github.com/dsx137/gg-kit/internal/generic"
 )
 
+type mapEntry struct {
+	mu rwLock
+	// upgrade is held by writers and by the upgradable reader, so an upgrade never lets a writer in. It is a
+	// channel of capacity one so LockCtx can stop waiting for it.
+	upgrade chan struct{}
+	refs    int
+	// id orders live entries for LockMany.
+	id uint64
+}
+
+// MapKeyedLocker keeps one RWMutex per key while anybody holds or waits for it, and forgets it afterwards.
 type MapKeyedLocker[K comparable] struct {
-	locks *generic.SyncMap[K, *sync.RWMutex]
+	mu     *sync.Mutex
+	locks  map[K]*mapEntry
+	nextID uint64
+	diag   *LockDiagnostics[K]
 }
 
 func NewMapKeyedLocker[K comparable]() *MapKeyedLocker[K] {
 	return &MapKeyedLocker[K]{
-		locks: generic.NewSyncMap[K, *sync.RWMutex](),
+		mu:    &sync.Mutex{},
+		locks: make(map[K]*mapEntry),
 	}
 }
 
-func (k *MapKeyedLocker[K]) Lock(key K) func() {
-	mu, _ := k.locks.LoadOrStore(key, &sync.RWMutex{})
-	mu.Lock()
+// Instrument makes k report to d. It must be called before k is used.
+func (k *MapKeyedLocker[K]) Instrument(d *LockDiagnostics[K]) *MapKeyedLocker[K] {
+	k.diag = d
+	return k
+}
 
-	return mu.Unlock
+func (k *MapKeyedLocker[K]) acquire(key K) *mapEntry {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e, ok := k.locks[key]
+	if !ok {
+		k.nextID++
+		e = &mapEntry{upgrade: make(chan struct{}, 1), id: k.nextID}
+		k.locks[key] = e
+	}
+	e.refs++
+	return e
 }
 
-func (k *MapKeyedLocker[K]) TryLock(key K) (func(), bool) {
-	mu, _ := k.locks.LoadOrStore(key, &sync.RWMutex{})
-	if !mu.TryLock() {
+func (k *MapKeyedLocker[K]) release(key K, e *mapEntry) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e.refs--
+	if e.refs == 0 {
+		delete(k.locks, key)
+	}
+}
+
+// funcs is rwFuncs for e, with writers also taking the upgrade mutex.
+func (e *mapEntry) funcs(mode LockMode) (lock func(), tryLock func() bool, unlock func()) {
+	if mode == LockRead {
+		return rwFuncs(&e.mu, mode)
+	}
+	lock = func() {
+		e.upgrade <- struct{}{}
+		e.mu.Lock()
+	}
+	tryLock = func() bool {
+		select {
+		case e.upgrade <- struct{}{}:
+		default:
+			return false
+		}
+		if !e.mu.TryLock() {
+			<-e.upgrade
+			return false
+		}
+		return true
+	}
+	unlock = func() {
+		e.mu.Unlock()
+		<-e.upgrade
+	}
+	return lock, tryLock, unlock
+}
+
+// lockCtx is the cancellable counterpart of the lock returned by funcs.
+func (e *mapEntry) lockCtx(ctx context.Context, mode LockMode) error {
+	if mode == LockRead {
+		return e.mu.lockCtx(ctx, mode)
+	}
+	select {
+	case e.upgrade <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := e.mu.lockCtx(ctx, mode); err != nil {
+		<-e.upgrade
+		return err
+	}
+	return nil
+}
+
+// held returns the entry of a key the caller holds.
+func (k *MapKeyedLocker[K]) held(key K) *mapEntry {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e, ok := k.locks[key]
+	if !ok {
+		panic("unlock of unlocked key")
+	}
+	return e
+}
+
+func (k *MapKeyedLocker[K]) lock(key K, mode LockMode) func() {
+	e := k.acquire(key)
+	lock, _, unlock := e.funcs(mode)
+	if k.diag == nil {
+		lock()
+		return func() {
+			unlock()
+			k.release(key, e)
+		}
+	}
+
+	res := lockRes{k, key}
+	gid := k.diag.lock(res, key, mode, lock)
+	return func() {
+		k.diag.released(gid, res)
+		unlock()
+		k.release(key, e)
+	}
+}
+
+func (k *MapKeyedLocker[K]) tryLock(key K, mode LockMode) (func(), bool) {
+	e := k.acquire(key)
+	_, tryLock, unlock := e.funcs(mode)
+	if !tryLock() {
+		k.release(key, e)
 		return nil, false
 	}
+	if k.diag == nil {
+		return func() {
+			unlock()
+			k.release(key, e)
+		}, true
+	}
 
-	return mu.Unlock, true
+	res := lockRes{k, key}
+	gid := k.diag.acquired(0, res, key, mode)
+	return func() {
+		k.diag.released(gid, res)
+		unlock()
+		k.release(key, e)
+	}, true
 }
 
-func (k *MapKeyedLocker[K]) RLock(key K) func() {
-	mu, _ := k.locks.LoadOrStore(key, &sync.RWMutex{})
-	mu.RLock()
+func (k *MapKeyedLocker[K]) lockCtx(ctx context.Context, key K, mode LockMode) (func(), error) {
+	e := k.acquire(key)
+	_, _, unlock := e.funcs(mode)
+	res := lockRes{k, key}
+	gid := 0
+	if k.diag != nil {
+		gid = k.diag.waiting(res, key, mode)
+	}
+	if err := e.lockCtx(ctx, mode); err != nil {
+		if k.diag != nil {
+			k.diag.abandoned(gid)
+		}
+		k.release(key, e)
+		return nil, err
+	}
+	if k.diag == nil {
+		return func() {
+			unlock()
+			k.release(key, e)
+		}, nil
+	}
 
-	return mu.RUnlock
+	k.diag.acquired(gid, res, key, mode)
+	return func() {
+		k.diag.released(gid, res)
+		unlock()
+		k.release(key, e)
+	}, nil
+}
+
+func (k *MapKeyedLocker[K]) Lock(key K) func() {
+	return k.lock(key, LockWrite)
+}
+
+func (k *MapKeyedLocker[K]) TryLock(key K) (func(), bool) {
+	return k.tryLock(key, LockWrite)
+}
+
+// LockCtx gives up with ctx.Err() once ctx is done, leaving the key untouched.
+func (k *MapKeyedLocker[K]) LockCtx(ctx context.Context, key K) (func(), error) {
+	return k.lockCtx(ctx, key, LockWrite)
+}
+
+func (k *MapKeyedLocker[K]) RLock(key K) func() {
+	return k.lock(key, LockRead)
 }
 
 func (k *MapKeyedLocker[K]) TryRLock(key K) (func(), bool) {
-	mu, _ := k.locks.LoadOrStore(key, &sync.RWMutex{})
-	if !mu.TryRLock() {
-		return nil, false
+	return k.tryLock(key, LockRead)
+}
+
+// RLockCtx gives up with ctx.Err() once ctx is done, leaving the key untouched.
+func (k *MapKeyedLocker[K]) RLockCtx(ctx context.Context, key K) (func(), error) {
+	return k.lockCtx(ctx, key, LockRead)
+}
+
+// LockMany locks every distinct key in an order shared by all callers, so overlapping calls cannot deadlock.
+func (k *MapKeyedLocker[K]) LockMany(keys ...K) func() {
+	return k.lockMany(keys, LockWrite)
+}
+
+func (k *MapKeyedLocker[K]) RLockMany(keys ...K) func() {
+	return k.lockMany(keys, LockRead)
+}
+
+func (k *MapKeyedLocker[K]) lockMany(keys []K, mode LockMode) func() {
+	type held struct {
+		key K
+		e   *mapEntry
+	}
+	seen := make(map[K]struct{}, len(keys))
+	all := make([]held, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		all = append(all, held{key: key, e: k.acquire(key)})
 	}
+	// Pinned entries are shared by everyone holding the same key, so their ids give a canonical order.
+	slices.SortFunc(all, func(a, b held) int { return cmp.Compare(a.e.id, b.e.id) })
 
-	return mu.RUnlock, true
+	gid := 0
+	for _, h := range all {
+		lock, _, _ := h.e.funcs(mode)
+		if k.diag == nil {
+			lock()
+		} else {
+			gid = k.diag.lock(lockRes{k, h.key}, h.key, mode, lock)
+		}
+	}
+	return func() {
+		for i := len(all) - 1; i >= 0; i-- {
+			_, _, unlock := all[i].e.funcs(mode)
+			if k.diag != nil {
+				k.diag.released(gid, lockRes{k, all[i].key})
+			}
+			unlock()
+			k.release(all[i].key, all[i].e)
+		}
+	}
 }
 
 func (k *MapKeyedLocker[K]) Locker(key K) sync.Locker {
-	mu, _ := k.locks.LoadOrStore(key, &sync.RWMutex{})
-	return mu
+	return mapLocker[K]{k: k, key: key, mode: LockWrite}
 }
 
 func (k *MapKeyedLocker[K]) RLocker(key K) sync.Locker {
-	mu, _ := k.locks.LoadOrStore(key, &sync.RWMutex{})
-	return mu.RLocker()
+	return mapLocker[K]{k: k, key: key, mode: LockRead}
+}
+
+// Len returns the number of keys currently held or waited on.
+func (k *MapKeyedLocker[K]) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.locks)
+}
+
+type mapLocker[K comparable] struct {
+	k    *MapKeyedLocker[K]
+	key  K
+	mode LockMode
+}
+
+func (l mapLocker[K]) Lock() {
+	e := l.k.acquire(l.key)
+	lock, _, _ := e.funcs(l.mode)
+	if l.k.diag == nil {
+		lock()
+		return
+	}
+	l.k.diag.lock(lockRes{l.k, l.key}, l.key, l.mode, lock)
+}
+
+func (l mapLocker[K]) Unlock() {
+	e := l.k.held(l.key)
+	_, _, unlock := e.funcs(l.mode)
+	if l.k.diag != nil {
+		l.k.diag.releasedAny(lockRes{l.k, l.key})
+	}
+	unlock()
+	l.k.release(l.key, e)
 }
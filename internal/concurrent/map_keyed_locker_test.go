@@ -0,0 +1,98 @@
+package concurrent_test
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+)
+
+func TestMapKeyedLockerEvicts(t *testing.T) {
+	l := concurrent.NewMapKeyedLocker[int]()
+	for i := 0; i < 1000; i++ {
+		l.Lock(i)()
+		l.RLock(i)()
+		if unlock, ok := l.TryLock(i); ok {
+			unlock()
+		}
+		locker := l.RLocker(i)
+		locker.Lock()
+		locker.Unlock()
+	}
+	if n := l.Len(); n != 0 {
+		t.Fatalf("expected no entries after all unlocks, got %d", n)
+	}
+
+	unlock := l.Lock(1)
+	if _, ok := l.TryLock(1); ok {
+		t.Fatal("TryLock succeeded on a held key")
+	}
+	if n := l.Len(); n != 1 {
+		t.Fatalf("expected the held key to stay, got %d entries", n)
+	}
+	unlock()
+	if n := l.Len(); n != 0 {
+		t.Fatalf("expected no entries, got %d", n)
+	}
+}
+
+// Holders of the same key must always share one mutex, even while entries are evicted and recreated:
+// two holders inside the critical section at once would mean they got different mutexes.
+func TestMapKeyedLockerEvictionStress(t *testing.T) {
+	const keys, goroutines, iterations = 4, 32, 2000
+	l := concurrent.NewMapKeyedLocker[int]()
+	var holders [keys]atomic.Int32
+
+	wg := &sync.WaitGroup{}
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				key := rand.IntN(keys)
+				var unlock func()
+				if i%3 == 0 {
+					var ok bool
+					if unlock, ok = l.TryLock(key); !ok {
+						continue
+					}
+				} else {
+					unlock = l.Lock(key)
+				}
+				if n := holders[key].Add(1); n != 1 {
+					t.Errorf("key %d held by %d goroutines at once", key, n)
+				}
+				holders[key].Add(-1)
+				unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := l.Len(); n != 0 {
+		t.Fatalf("expected all entries to be evicted, got %d", n)
+	}
+}
+
+func TestMapKeyedLockerCtxEvicts(t *testing.T) {
+	l := concurrent.NewMapKeyedLocker[int]()
+	unlock := l.Lock(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.LockCtx(ctx, 1); err == nil {
+		t.Fatal("expected LockCtx to time out")
+	}
+	unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for l.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the abandoned attempt to release its entry, got %d entries", l.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,234 @@
+package concurrent
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/dsx137/gg-kit/internal/lang"
+)
+
+// pathMode is a lock mode of a single node. Intention modes are taken on the ancestors of the locked path.
+type pathMode int
+
+const (
+	pathIS pathMode = iota // a descendant is read-locked
+	pathIX                 // a descendant is write-locked
+	pathS
+	pathX
+)
+
+// pathCompatible tells which modes a node can hold at once.
+var pathCompatible = [4][4]bool{
+	pathIS: {pathIS: true, pathIX: true, pathS: true},
+	pathIX: {pathIS: true, pathIX: true},
+	pathS:  {pathIS: true, pathS: true},
+	pathX:  {},
+}
+
+// pathRank orders the modes for writer preference: a request yields to waiting requests of a conflicting,
+// higher-ranked mode, so writers of a subtree, and then writers below it, go before readers.
+var pathRank = [4]int{pathIS: 0, pathS: 1, pathIX: 2, pathX: 3}
+
+type pathNode struct {
+	held [4]int
+	// pending counts the requests of each mode blocked on the node.
+	pending [4]int
+	refs    int
+	wake    chan struct{}
+}
+
+func (n *pathNode) admits(m pathMode) bool {
+	for h, c := range n.held {
+		if c > 0 && !pathCompatible[h][m] {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *pathNode) yields(m pathMode) bool {
+	for p, c := range n.pending {
+		if c > 0 && pathRank[p] > pathRank[m] && !pathCompatible[p][m] {
+			return true
+		}
+	}
+	return false
+}
+
+type pathStep struct {
+	path string
+	mode pathMode
+}
+
+// PathLocker locks slash-separated paths as subtrees: a lock on /a/b covers /a/b/c, so it conflicts with
+// locks on descendants as well as with a write lock on /a. It works like a database lock manager, taking
+// intention locks on every ancestor from the root down, which keeps it deadlock-free as long as each
+// goroutine holds at most one path at a time. Waiting writers hold off new readers of the same subtree, so
+// a stream of overlapping readers cannot starve them. Paths are cleaned and made absolute first, so "a/b/"
+// and "/a/b" are the same path.
+type PathLocker struct {
+	mu    *sync.Mutex
+	nodes map[string]*pathNode
+}
+
+func NewPathLocker() *PathLocker {
+	return &PathLocker{
+		mu:    &sync.Mutex{},
+		nodes: make(map[string]*pathNode),
+	}
+}
+
+// pathSteps lists the nodes to lock for p, root first.
+func pathSteps(p string, mode LockMode) []pathStep {
+	intent, final := pathIX, pathX
+	if mode == LockRead {
+		intent, final = pathIS, pathS
+	}
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return []pathStep{{"/", final}}
+	}
+
+	parts := strings.Split(p[1:], "/")
+	steps := make([]pathStep, 0, len(parts)+1)
+	steps = append(steps, pathStep{"/", intent})
+	for i := 1; i < len(parts); i++ {
+		steps = append(steps, pathStep{"/" + strings.Join(parts[:i], "/"), intent})
+	}
+	return append(steps, pathStep{p, final})
+}
+
+func (k *PathLocker) pinLocked(p string) *pathNode {
+	n, ok := k.nodes[p]
+	if !ok {
+		n = &pathNode{}
+		k.nodes[p] = n
+	}
+	n.refs++
+	return n
+}
+
+func (k *PathLocker) unpinLocked(p string, n *pathNode) {
+	n.refs--
+	if n.refs == 0 {
+		delete(k.nodes, p)
+	}
+}
+
+func (k *PathLocker) lock(p string, mode LockMode) func() {
+	steps := pathSteps(p, mode)
+
+	k.mu.Lock()
+	for _, s := range steps {
+		n := k.pinLocked(s.path)
+		waited := false
+		for !n.admits(s.mode) || n.yields(s.mode) {
+			if !waited {
+				waited = true
+				n.pending[s.mode]++
+			}
+			if n.wake == nil {
+				n.wake = make(chan struct{})
+			}
+			wake := n.wake
+			k.mu.Unlock()
+			<-wake
+			k.mu.Lock()
+		}
+		if waited {
+			// Whoever yielded to this request now finds it held instead, which conflicts just the same.
+			n.pending[s.mode]--
+		}
+		n.held[s.mode]++
+	}
+	k.mu.Unlock()
+
+	return func() { k.unlock(steps) }
+}
+
+func (k *PathLocker) tryLock(p string, mode LockMode) (func(), bool) {
+	steps := pathSteps(p, mode)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for i, s := range steps {
+		n := k.pinLocked(s.path)
+		if !n.admits(s.mode) || n.yields(s.mode) {
+			k.unpinLocked(s.path, n)
+			// Nobody can have started waiting on what was taken so far, so nobody needs waking either.
+			for _, s := range steps[:i] {
+				n := k.nodes[s.path]
+				n.held[s.mode]--
+				k.unpinLocked(s.path, n)
+			}
+			return nil, false
+		}
+		n.held[s.mode]++
+	}
+	return func() { k.unlock(steps) }, true
+}
+
+func (k *PathLocker) unlock(steps []pathStep) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	for _, s := range steps {
+		if n, ok := k.nodes[s.path]; !ok || n.held[s.mode] == 0 {
+			panic("unlock of unlocked path")
+		}
+	}
+	for i := len(steps) - 1; i >= 0; i-- {
+		s := steps[i]
+		n := k.nodes[s.path]
+		n.held[s.mode]--
+		n.wake = lang.Wake(n.wake)
+		k.unpinLocked(s.path, n)
+	}
+}
+
+// Lock write-locks the subtree at p.
+func (k *PathLocker) Lock(p string) func() {
+	return k.lock(p, LockWrite)
+}
+
+func (k *PathLocker) TryLock(p string) (func(), bool) {
+	return k.tryLock(p, LockWrite)
+}
+
+// RLock read-locks the subtree at p.
+func (k *PathLocker) RLock(p string) func() {
+	return k.lock(p, LockRead)
+}
+
+func (k *PathLocker) TryRLock(p string) (func(), bool) {
+	return k.tryLock(p, LockRead)
+}
+
+func (k *PathLocker) Locker(p string) sync.Locker {
+	return pathLocker{k: k, path: p, mode: LockWrite}
+}
+
+func (k *PathLocker) RLocker(p string) sync.Locker {
+	return pathLocker{k: k, path: p, mode: LockRead}
+}
+
+// Len returns the number of paths and ancestors currently locked or waited on.
+func (k *PathLocker) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.nodes)
+}
+
+type pathLocker struct {
+	k    *PathLocker
+	path string
+	mode LockMode
+}
+
+func (l pathLocker) Lock() {
+	l.k.lock(l.path, l.mode)
+}
+
+func (l pathLocker) Unlock() {
+	l.k.unlock(pathSteps(l.path, l.mode))
+}
@@ -0,0 +1,180 @@
+package concurrent_test
+
+import (
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+	"github.com/dsx137/gg-kit/internal/concurrent/lockertest"
+)
+
+func TestPathLocker(t *testing.T) {
+	lockertest.Run(t, func() concurrent.KeyedLocker[string] {
+		return concurrent.NewPathLocker()
+	})
+}
+
+func TestPathLockerHierarchy(t *testing.T) {
+	type probe struct {
+		path  string
+		write bool
+		want  bool
+	}
+	for _, tt := range []struct {
+		name   string
+		path   string
+		write  bool
+		probes []probe
+	}{
+		{"write parent", "/a", true, []probe{
+			{"/a/b", false, false},
+			{"/a/b", true, false},
+			{"/", false, false},
+			{"/x", true, true},
+		}},
+		{"read parent", "/a", false, []probe{
+			{"/a", false, true},
+			{"/a/b", false, true},
+			{"/a/b", true, false},
+			{"/x", true, true},
+		}},
+		{"write child", "/a/b", true, []probe{
+			{"/a", true, false},
+			{"/a", false, false},
+			{"/a/b/c", false, false},
+			{"/a/c", true, true},
+			{"/a/bc", true, true},
+		}},
+		{"read child", "/a/b", false, []probe{
+			{"/a", false, true},
+			{"/a", true, false},
+			{"/a/b/c", false, true},
+			{"/a/b/c", true, false},
+		}},
+		{"unclean path", "a//b/", true, []probe{
+			{"/a/b", false, false},
+			{"/a/c", true, true},
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l := concurrent.NewPathLocker()
+			unlock := l.RLock(tt.path)
+			if tt.write {
+				unlock()
+				unlock = l.Lock(tt.path)
+			}
+			for _, p := range tt.probes {
+				if got := lockertest.TryElsewhere(l, p.path, p.write); got != p.want {
+					t.Errorf("lock of %s (write %v) while holding %s: got %v, want %v", p.path, p.write, tt.path, got, p.want)
+				}
+			}
+			unlock()
+			if n := l.Len(); n != 0 {
+				t.Fatalf("expected no nodes after all unlocks, got %d", n)
+			}
+		})
+	}
+}
+
+func TestPathLockerWaitsForAncestor(t *testing.T) {
+	l := concurrent.NewPathLocker()
+	unlock := l.Lock("/a")
+	acquired := make(chan struct{})
+	go func() {
+		unlock := l.Lock("/a/b/c")
+		close(acquired)
+		unlock()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("descendant was locked while its ancestor was write-locked")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("descendant was not locked after the ancestor was released")
+	}
+}
+
+func TestPathLockerWriterNotStarved(t *testing.T) {
+	l := concurrent.NewPathLocker()
+	stop := make(chan struct{})
+	readers := &sync.WaitGroup{}
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		// Every reader overlaps the next, so /a is never free of readers unless new ones hold back.
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			p := []string{"/a", "/a/b"}[i%2]
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				unlock := l.RLock(p)
+				time.Sleep(5 * time.Millisecond)
+				unlock()
+			}()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	acquired := make(chan struct{})
+	go func() {
+		l.Lock("/a")()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("writer was starved by overlapping readers")
+	}
+	close(stop)
+	readers.Wait()
+	if n := l.Len(); n != 0 {
+		t.Fatalf("expected no nodes after all unlocks, got %d", n)
+	}
+}
+
+func TestPathLockerStress(t *testing.T) {
+	l := concurrent.NewPathLocker()
+	paths := []string{"/", "/a", "/a/b", "/a/b/c", "/a/d", "/e", "/e/f"}
+	// The race detector flags a write and a read of the same counter unless the locks exclude each other.
+	counters := make(map[string]*int, len(paths))
+	for _, p := range paths {
+		counters[p] = new(int)
+	}
+
+	wg := &sync.WaitGroup{}
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 300 {
+				p := paths[rand.IntN(len(paths))]
+				if rand.IntN(2) == 0 {
+					unlock := l.RLock(p)
+					_ = *counters[p]
+					unlock()
+					continue
+				}
+				unlock := l.Lock(p)
+				*counters[p]++
+				unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if n := l.Len(); n != 0 {
+		t.Fatalf("expected no nodes after all unlocks, got %d", n)
+	}
+}
@@ -0,0 +1,223 @@
+package concurrent
+
+import (
+	"sync"
+
+	"github.com/dsx137/gg-kit/internal/lang"
+)
+
+type reentrantEntry struct {
+	owner          int
+	writes         int
+	readers        map[int]int
+	writersWaiting int
+	waiters        int
+	wake           chan struct{}
+}
+
+const errUpgrade = "concurrent: cannot upgrade a read lock to a write lock"
+
+func (e *reentrantEntry) idle() bool {
+	return e.owner == 0 && len(e.readers) == 0 && e.waiters == 0
+}
+
+// ReentrantKeyedLocker lets the goroutine holding a key lock it again, in either mode for a write lock
+// and in read mode for a read lock; every lock must be undone by its own unlock. Goroutines are identified
+// with lang.GetGoroutineId. Waiting writers hold off new readers. Unlocking from another goroutine or
+// upgrading a read lock panics.
+type ReentrantKeyedLocker[K comparable] struct {
+	mu    *sync.Mutex
+	locks map[K]*reentrantEntry
+}
+
+func NewReentrantKeyedLocker[K comparable]() *ReentrantKeyedLocker[K] {
+	return &ReentrantKeyedLocker[K]{
+		mu:    &sync.Mutex{},
+		locks: make(map[K]*reentrantEntry),
+	}
+}
+
+func (k *ReentrantKeyedLocker[K]) entryLocked(key K) *reentrantEntry {
+	e, ok := k.locks[key]
+	if !ok {
+		e = &reentrantEntry{readers: make(map[int]int)}
+		k.locks[key] = e
+	}
+	return e
+}
+
+func (k *ReentrantKeyedLocker[K]) evictLocked(key K, e *reentrantEntry) {
+	if e.idle() {
+		delete(k.locks, key)
+	}
+}
+
+// upgrade reports whether gid asks for a write lock while only holding a read lock, which would never be granted.
+func (e *reentrantEntry) upgrade(gid int, mode LockMode) bool {
+	return mode == LockWrite && e.owner != gid && e.readers[gid] > 0
+}
+
+// tryLocked takes the lock for gid if it can.
+func (k *ReentrantKeyedLocker[K]) tryLocked(e *reentrantEntry, gid int, mode LockMode) bool {
+	if mode == LockWrite {
+		switch {
+		case e.owner == gid:
+			e.writes++
+			return true
+		case e.owner == 0 && len(e.readers) == 0:
+			e.owner, e.writes = gid, 1
+			return true
+		}
+		return false
+	}
+
+	if e.owner == gid || e.readers[gid] > 0 || e.owner == 0 && e.writersWaiting == 0 {
+		e.readers[gid]++
+		return true
+	}
+	return false
+}
+
+func (k *ReentrantKeyedLocker[K]) lock(key K, mode LockMode) func() {
+	gid := lang.GetGoroutineId()
+
+	k.mu.Lock()
+	e := k.entryLocked(key)
+	if e.upgrade(gid, mode) {
+		k.mu.Unlock()
+		panic(errUpgrade)
+	}
+	if !k.tryLocked(e, gid, mode) {
+		e.waiters++
+		if mode == LockWrite {
+			e.writersWaiting++
+		}
+		for {
+			if e.wake == nil {
+				e.wake = make(chan struct{})
+			}
+			wake := e.wake
+			k.mu.Unlock()
+			<-wake
+			k.mu.Lock()
+
+			if mode == LockWrite {
+				e.writersWaiting--
+			}
+			if k.tryLocked(e, gid, mode) {
+				break
+			}
+			if mode == LockWrite {
+				e.writersWaiting++
+			}
+		}
+		e.waiters--
+	}
+	k.mu.Unlock()
+
+	return k.unlocker(key, e, gid, mode)
+}
+
+func (k *ReentrantKeyedLocker[K]) tryLock(key K, mode LockMode) (func(), bool) {
+	gid := lang.GetGoroutineId()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	e := k.entryLocked(key)
+	if e.upgrade(gid, mode) {
+		panic(errUpgrade)
+	}
+	if !k.tryLocked(e, gid, mode) {
+		k.evictLocked(key, e)
+		return nil, false
+	}
+	return k.unlocker(key, e, gid, mode), true
+}
+
+func (k *ReentrantKeyedLocker[K]) unlocker(key K, e *reentrantEntry, gid int, mode LockMode) func() {
+	return func() {
+		if lang.GetGoroutineId() != gid {
+			panic("concurrent: unlock of a reentrant lock from a goroutine that does not hold it")
+		}
+		k.unlock(key, e, gid, mode)
+	}
+}
+
+func (k *ReentrantKeyedLocker[K]) unlock(key K, e *reentrantEntry, gid int, mode LockMode) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if mode == LockWrite {
+		if e.owner != gid {
+			panic("concurrent: unlock of a key not write-locked by this goroutine")
+		}
+		e.writes--
+		if e.writes == 0 {
+			e.owner = 0
+		}
+	} else {
+		n, ok := e.readers[gid]
+		if !ok {
+			panic("concurrent: runlock of a key not read-locked by this goroutine")
+		}
+		if n == 1 {
+			delete(e.readers, gid)
+		} else {
+			e.readers[gid] = n - 1
+		}
+	}
+
+	e.wake = lang.Wake(e.wake)
+	k.evictLocked(key, e)
+}
+
+func (k *ReentrantKeyedLocker[K]) Lock(key K) func() {
+	return k.lock(key, LockWrite)
+}
+
+func (k *ReentrantKeyedLocker[K]) TryLock(key K) (func(), bool) {
+	return k.tryLock(key, LockWrite)
+}
+
+func (k *ReentrantKeyedLocker[K]) RLock(key K) func() {
+	return k.lock(key, LockRead)
+}
+
+func (k *ReentrantKeyedLocker[K]) TryRLock(key K) (func(), bool) {
+	return k.tryLock(key, LockRead)
+}
+
+func (k *ReentrantKeyedLocker[K]) Locker(key K) sync.Locker {
+	return reentrantLocker[K]{k: k, key: key, mode: LockWrite}
+}
+
+func (k *ReentrantKeyedLocker[K]) RLocker(key K) sync.Locker {
+	return reentrantLocker[K]{k: k, key: key, mode: LockRead}
+}
+
+// Len returns the number of keys currently held or waited on.
+func (k *ReentrantKeyedLocker[K]) Len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.locks)
+}
+
+type reentrantLocker[K comparable] struct {
+	k    *ReentrantKeyedLocker[K]
+	key  K
+	mode LockMode
+}
+
+func (l reentrantLocker[K]) Lock() {
+	l.k.lock(l.key, l.mode)
+}
+
+func (l reentrantLocker[K]) Unlock() {
+	l.k.mu.Lock()
+	e, ok := l.k.locks[l.key]
+	l.k.mu.Unlock()
+	if !ok {
+		panic("concurrent: unlock of an unlocked key")
+	}
+	l.k.unlock(l.key, e, lang.GetGoroutineId(), l.mode)
+}
@@ -0,0 +1,73 @@
+package concurrent_test
+
+import (
+	"testing"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+	"github.com/dsx137/gg-kit/internal/concurrent/lockertest"
+)
+
+func TestReentrantKeyedLocker(t *testing.T) {
+	lockertest.Run(t, func() concurrent.KeyedLocker[string] {
+		return concurrent.NewReentrantKeyedLocker[string]()
+	})
+}
+
+func TestReentrantKeyedLockerReenters(t *testing.T) {
+	l := concurrent.NewReentrantKeyedLocker[string]()
+
+	var walk func(depth int)
+	walk = func(depth int) {
+		unlock := l.Lock("tree")
+		defer unlock()
+		runlock := l.RLock("tree")
+		defer runlock()
+		if depth > 0 {
+			walk(depth - 1)
+		}
+	}
+	walk(5)
+	if n := l.Len(); n != 0 {
+		t.Fatalf("expected the key to be released, got %d entries", n)
+	}
+
+	outer := l.Lock("k")
+	inner := l.Lock("k")
+	inner()
+	if lockertest.TryElsewhere(l, "k", true) {
+		t.Fatal("key was released before the outer unlock")
+	}
+	outer()
+	if !lockertest.TryElsewhere(l, "k", true) {
+		t.Fatal("key was not released by the outer unlock")
+	}
+}
+
+func TestReentrantKeyedLockerMisuse(t *testing.T) {
+	l := concurrent.NewReentrantKeyedLocker[string]()
+
+	runlock := l.RLock("k")
+	expectPanic(t, "upgrade", func() { l.Lock("k") })
+	runlock()
+
+	unlock := l.Lock("k")
+	done := make(chan any)
+	go func() {
+		defer func() { done <- recover() }()
+		unlock()
+	}()
+	if <-done == nil {
+		t.Fatal("expected unlock from another goroutine to panic")
+	}
+	unlock()
+}
+
+func expectPanic(t *testing.T, what string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected %s to panic", what)
+		}
+	}()
+	f()
+}
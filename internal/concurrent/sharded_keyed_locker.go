@@ -1,13 +1,28 @@
 package concurrent
 
 import (
+	"cmp"
+	"context"
+	"slices"
 	"sync"
+	"unsafe"
 )
 
+// cacheLine is the false-sharing granularity assumed for padding; 64 bytes covers amd64 and most arm64.
+const cacheLine = 64
+
+// paddedRWMutex gives each shard a cache line of its own, so goroutines on different shards don't
+// invalidate each other's lines.
+type paddedRWMutex struct {
+	mu rwLock
+	_  [cacheLine - unsafe.Sizeof(rwLock{})%cacheLine]byte
+}
+
 type ShardedKeyedLocker[K comparable] struct {
-	shards []sync.RWMutex
+	shards []paddedRWMutex
 	mask   uint64
 	hash   func(K) uint64
+	diag   *LockDiagnostics[K]
 }
 
 func NewShardedKeyedLocker[K comparable](exp uint, hash func(K) uint64) *ShardedKeyedLocker[K] {
@@ -16,46 +31,184 @@ func NewShardedKeyedLocker[K comparable](exp uint, hash func(K) uint64) *Sharded
 	}
 	shardCount := 1 << exp
 	return &ShardedKeyedLocker[K]{
-		shards: make([]sync.RWMutex, shardCount),
+		shards: make([]paddedRWMutex, shardCount),
 		mask:   uint64(shardCount - 1),
 		hash:   hash,
 	}
 }
 
-func (k *ShardedKeyedLocker[K]) Lock(key K) func() {
-	mu := &k.shards[k.hash(key)&k.mask]
-	mu.Lock()
-	return mu.Unlock
+// NewDefaultShardedKeyedLocker shards keys with a NewHasher of its own.
+func NewDefaultShardedKeyedLocker[K comparable](exp uint) *ShardedKeyedLocker[K] {
+	return NewShardedKeyedLocker(exp, NewHasher[K]())
 }
 
-func (k *ShardedKeyedLocker[K]) TryLock(key K) (func(), bool) {
-	mu := &k.shards[k.hash(key)&k.mask]
-	if !mu.TryLock() {
+// Instrument makes k report to d. It must be called before k is used. Keys sharing a shard show up as
+// waiting for each other.
+func (k *ShardedKeyedLocker[K]) Instrument(d *LockDiagnostics[K]) *ShardedKeyedLocker[K] {
+	k.diag = d
+	return k
+}
+
+func (k *ShardedKeyedLocker[K]) shard(key K) uint64 {
+	return k.hash(key) & k.mask
+}
+
+func (k *ShardedKeyedLocker[K]) lock(key K, mode LockMode) func() {
+	i := k.shard(key)
+	lock, _, unlock := rwFuncs(&k.shards[i].mu, mode)
+	if k.diag == nil {
+		lock()
+		return unlock
+	}
+
+	res := lockRes{k, i}
+	gid := k.diag.lock(res, key, mode, lock)
+	return func() {
+		k.diag.released(gid, res)
+		unlock()
+	}
+}
+
+func (k *ShardedKeyedLocker[K]) tryLock(key K, mode LockMode) (func(), bool) {
+	i := k.shard(key)
+	_, tryLock, unlock := rwFuncs(&k.shards[i].mu, mode)
+	if !tryLock() {
 		return nil, false
 	}
-	return mu.Unlock, true
+	if k.diag == nil {
+		return unlock, true
+	}
+
+	res := lockRes{k, i}
+	gid := k.diag.acquired(0, res, key, mode)
+	return func() {
+		k.diag.released(gid, res)
+		unlock()
+	}, true
+}
+
+func (k *ShardedKeyedLocker[K]) lockCtx(ctx context.Context, key K, mode LockMode) (func(), error) {
+	i := k.shard(key)
+	mu := &k.shards[i].mu
+	_, _, unlock := rwFuncs(mu, mode)
+	if k.diag == nil {
+		if err := mu.lockCtx(ctx, mode); err != nil {
+			return nil, err
+		}
+		return unlock, nil
+	}
+
+	res := lockRes{k, i}
+	gid := k.diag.waiting(res, key, mode)
+	if err := mu.lockCtx(ctx, mode); err != nil {
+		k.diag.abandoned(gid)
+		return nil, err
+	}
+	k.diag.acquired(gid, res, key, mode)
+	return func() {
+		k.diag.released(gid, res)
+		unlock()
+	}, nil
+}
+
+func (k *ShardedKeyedLocker[K]) Lock(key K) func() {
+	return k.lock(key, LockWrite)
+}
+
+func (k *ShardedKeyedLocker[K]) TryLock(key K) (func(), bool) {
+	return k.tryLock(key, LockWrite)
+}
+
+// LockCtx gives up with ctx.Err() once ctx is done, leaving the shard untouched.
+func (k *ShardedKeyedLocker[K]) LockCtx(ctx context.Context, key K) (func(), error) {
+	return k.lockCtx(ctx, key, LockWrite)
 }
 
 func (k *ShardedKeyedLocker[K]) RLock(key K) func() {
-	mu := &k.shards[k.hash(key)&k.mask]
-	mu.RLock()
-	return mu.RUnlock
+	return k.lock(key, LockRead)
 }
 
 func (k *ShardedKeyedLocker[K]) TryRLock(key K) (func(), bool) {
-	mu := &k.shards[k.hash(key)&k.mask]
-	if !mu.TryRLock() {
-		return nil, false
+	return k.tryLock(key, LockRead)
+}
+
+// RLockCtx gives up with ctx.Err() once ctx is done, leaving the shard untouched.
+func (k *ShardedKeyedLocker[K]) RLockCtx(ctx context.Context, key K) (func(), error) {
+	return k.lockCtx(ctx, key, LockRead)
+}
+
+// LockMany locks the shards of all keys in ascending order, taking a shard shared by several keys only once.
+func (k *ShardedKeyedLocker[K]) LockMany(keys ...K) func() {
+	return k.lockMany(keys, LockWrite)
+}
+
+func (k *ShardedKeyedLocker[K]) RLockMany(keys ...K) func() {
+	return k.lockMany(keys, LockRead)
+}
+
+func (k *ShardedKeyedLocker[K]) lockMany(keys []K, mode LockMode) func() {
+	type shardKey struct {
+		i   uint64
+		key K
+	}
+	shards := make([]shardKey, len(keys))
+	for j, key := range keys {
+		shards[j] = shardKey{k.shard(key), key}
+	}
+	slices.SortFunc(shards, func(a, b shardKey) int { return cmp.Compare(a.i, b.i) })
+	shards = slices.CompactFunc(shards, func(a, b shardKey) bool { return a.i == b.i })
+
+	gid := 0
+	for _, s := range shards {
+		lock, _, _ := rwFuncs(&k.shards[s.i].mu, mode)
+		if k.diag == nil {
+			lock()
+		} else {
+			gid = k.diag.lock(lockRes{k, s.i}, s.key, mode, lock)
+		}
+	}
+	return func() {
+		for j := len(shards) - 1; j >= 0; j-- {
+			_, _, unlock := rwFuncs(&k.shards[shards[j].i].mu, mode)
+			if k.diag != nil {
+				k.diag.released(gid, lockRes{k, shards[j].i})
+			}
+			unlock()
+		}
 	}
-	return mu.RUnlock, true
 }
 
 func (k *ShardedKeyedLocker[K]) Locker(key K) sync.Locker {
-	mu := &k.shards[k.hash(key)&k.mask]
+	if k.diag != nil {
+		return shardLocker[K]{k: k, key: key, mode: LockWrite}
+	}
+	mu := &k.shards[k.shard(key)].mu
 	return mu
 }
 
 func (k *ShardedKeyedLocker[K]) RLocker(key K) sync.Locker {
-	mu := &k.shards[k.hash(key)&k.mask]
+	if k.diag != nil {
+		return shardLocker[K]{k: k, key: key, mode: LockRead}
+	}
+	mu := &k.shards[k.shard(key)].mu
 	return mu.RLocker()
 }
+
+type shardLocker[K comparable] struct {
+	k    *ShardedKeyedLocker[K]
+	key  K
+	mode LockMode
+}
+
+func (l shardLocker[K]) Lock() {
+	i := l.k.shard(l.key)
+	lock, _, _ := rwFuncs(&l.k.shards[i].mu, l.mode)
+	l.k.diag.lock(lockRes{l.k, i}, l.key, l.mode, lock)
+}
+
+func (l shardLocker[K]) Unlock() {
+	i := l.k.shard(l.key)
+	_, _, unlock := rwFuncs(&l.k.shards[i].mu, l.mode)
+	l.k.diag.releasedAny(lockRes{l.k, i})
+	unlock()
+}
@@ -0,0 +1,40 @@
+package concurrent_test
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+)
+
+// BenchmarkShardedKeyedLocker has every goroutine lock a key of its own in a neighbouring shard, so the
+// only contention left is false sharing between the shard locks. padded is the locker as built by
+// NewShardedKeyedLocker, dense a stand-in locking the same way with its shard locks packed together. Any
+// difference between them can only show with GOMAXPROCS above 1 on a multi-core machine.
+func BenchmarkShardedKeyedLocker(b *testing.B) {
+	identity := func(i int) uint64 { return uint64(i) }
+	for _, procs := range []int{1, 2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("procs=%d", procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+
+			padded := concurrent.NewShardedKeyedLocker(6, identity)
+			b.Run("padded/Lock", func(b *testing.B) { benchOwnKey(b, func(key int) { padded.Lock(key)() }) })
+			b.Run("padded/RLock", func(b *testing.B) { benchOwnKey(b, func(key int) { padded.RLock(key)() }) })
+			dense := concurrent.NewDenseShardedKeyedLocker(6, identity)
+			b.Run("dense/Lock", func(b *testing.B) { benchOwnKey(b, func(key int) { dense.Lock(key)() }) })
+			b.Run("dense/RLock", func(b *testing.B) { benchOwnKey(b, func(key int) { dense.RLock(key)() }) })
+		})
+	}
+}
+
+func benchOwnKey(b *testing.B, lockUnlock func(key int)) {
+	var next atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		key := int(next.Add(1)-1) % 64
+		for pb.Next() {
+			lockUnlock(key)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package concurrent
+
+// UpgradableLock is a read lock on a key of a MapKeyedLocker that can be turned into a write lock and back
+// without letting another writer in between. It belongs to the goroutine that took it.
+type UpgradableLock[K comparable] struct {
+	k        *MapKeyedLocker[K]
+	key      K
+	e        *mapEntry
+	gid      int
+	upgraded bool
+	unlocked bool
+}
+
+// RLockUpgradable read-locks key alongside plain readers, but excludes writers and other upgradable readers.
+// Since at most one reader of a key can be waiting to upgrade, upgrades cannot deadlock each other.
+func (k *MapKeyedLocker[K]) RLockUpgradable(key K) *UpgradableLock[K] {
+	e := k.acquire(key)
+	lock := func() {
+		e.upgrade <- struct{}{}
+		e.mu.RLock()
+	}
+	l := &UpgradableLock[K]{k: k, key: key, e: e}
+	if k.diag == nil {
+		lock()
+	} else {
+		l.gid = k.diag.lock(lockRes{k, key}, key, LockRead, lock)
+	}
+	return l
+}
+
+// Upgrade waits for the plain readers to leave and turns l into a write lock.
+func (l *UpgradableLock[K]) Upgrade() {
+	if l.unlocked {
+		panic("upgrade of unlocked key")
+	}
+	if l.upgraded {
+		panic("upgrade of write-locked key")
+	}
+	l.upgraded = true
+	l.e.mu.RUnlock()
+	if l.k.diag == nil {
+		l.e.mu.Lock()
+		return
+	}
+	res := lockRes{l.k, l.key}
+	l.k.diag.released(l.gid, res)
+	l.k.diag.lock(res, l.key, LockWrite, l.e.mu.Lock)
+}
+
+// Downgrade turns an upgraded l back into an upgradable read lock, letting plain readers in again.
+func (l *UpgradableLock[K]) Downgrade() {
+	if l.unlocked || !l.upgraded {
+		panic("downgrade of key that is not write-locked")
+	}
+	l.upgraded = false
+	l.e.mu.Unlock()
+	l.e.mu.RLock()
+	if l.k.diag != nil {
+		res := lockRes{l.k, l.key}
+		l.k.diag.released(l.gid, res)
+		l.k.diag.acquired(l.gid, res, l.key, LockRead)
+	}
+}
+
+// Unlock releases l in whichever mode it is in.
+func (l *UpgradableLock[K]) Unlock() {
+	if l.unlocked {
+		panic("unlock of unlocked key")
+	}
+	l.unlocked = true
+	if l.k.diag != nil {
+		l.k.diag.released(l.gid, lockRes{l.k, l.key})
+	}
+	if l.upgraded {
+		l.e.mu.Unlock()
+	} else {
+		l.e.mu.RUnlock()
+	}
+	<-l.e.upgrade
+	l.k.release(l.key, l.e)
+}
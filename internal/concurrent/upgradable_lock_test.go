@@ -0,0 +1,94 @@
+package concurrent_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dsx137/gg-kit/internal/concurrent"
+	"github.com/dsx137/gg-kit/internal/concurrent/lockertest"
+)
+
+func TestUpgradableLock(t *testing.T) {
+	d := concurrent.NewLockDiagnostics[string](0, nil)
+	for name, l := range map[string]*concurrent.MapKeyedLocker[string]{
+		"plain":        concurrent.NewMapKeyedLocker[string](),
+		"instrumented": concurrent.NewMapKeyedLocker[string]().Instrument(d),
+	} {
+		t.Run(name, func(t *testing.T) { testUpgradableLock(t, l) })
+	}
+	if s := d.Dump(); len(s.Holders) != 0 || len(s.Waiters) != 0 {
+		t.Fatalf("expected no holders or waiters left, got %+v", s)
+	}
+}
+
+func testUpgradableLock(t *testing.T, l *concurrent.MapKeyedLocker[string]) {
+	u := l.RLockUpgradable("k")
+	if !lockertest.TryElsewhere(l, "k", false) {
+		t.Fatal("an upgradable reader excluded plain readers")
+	}
+	if lockertest.TryElsewhere(l, "k", true) {
+		t.Fatal("an upgradable reader let a writer in")
+	}
+
+	other := make(chan struct{})
+	otherDone := make(chan struct{})
+	go func() {
+		defer close(otherDone)
+		u := l.RLockUpgradable("k")
+		close(other)
+		u.Unlock()
+	}()
+	select {
+	case <-other:
+		t.Fatal("two upgradable readers held the same key")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	var writerIn atomic.Bool
+	writerDone := make(chan struct{})
+	go func() {
+		unlock := l.Lock("k")
+		writerIn.Store(true)
+		unlock()
+		close(writerDone)
+	}()
+
+	var readerLeft atomic.Bool
+	readerIn := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		runlock := l.RLock("k")
+		close(readerIn)
+		time.Sleep(20 * time.Millisecond)
+		readerLeft.Store(true)
+		runlock()
+	}()
+	<-readerIn
+	u.Upgrade()
+	if !readerLeft.Load() {
+		t.Fatal("upgraded while a plain reader held the key")
+	}
+	if lockertest.TryElsewhere(l, "k", false) {
+		t.Fatal("a reader got in after the upgrade")
+	}
+
+	u.Downgrade()
+	if !lockertest.TryElsewhere(l, "k", false) {
+		t.Fatal("readers were not let in after the downgrade")
+	}
+	if writerIn.Load() {
+		t.Fatal("a writer slipped in while the key was upgraded")
+	}
+
+	u.Unlock()
+	// Wait until every goroutine has unlocked and let go of the entry, not just until it got in.
+	<-otherDone
+	<-readerDone
+	<-writerDone
+	expectPanic(t, "a second unlock", u.Unlock)
+	if n := l.Len(); n != 0 {
+		t.Fatalf("expected no entries after all unlocks, got %d", n)
+	}
+}
@@ -0,0 +1,12 @@
+package lang
+
+// Wake releases everyone waiting on ch and returns nil, so the next waiter allocates a fresh channel.
+// It backs the wake channels of the channel and concurrent packages.
+//
+// ggkit:ignore
+func Wake(ch chan struct{}) chan struct{} {
+	if ch != nil {
+		close(ch)
+	}
+	return nil
+}
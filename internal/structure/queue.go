@@ -26,6 +26,15 @@ func (q *Queue[T]) Dequeue() (v T, ok bool) {
 	return front.Value(), true
 }
 
+func (q *Queue[T]) Peek() (v T, ok bool) {
+	front := q.l.Front()
+	if front == nil {
+		var zero T
+		return zero, false
+	}
+	return front.Value(), true
+}
+
 func (q *Queue[T]) Len() int {
 	return q.l.Len()
 }
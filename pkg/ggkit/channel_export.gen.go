@@ -4,14 +4,152 @@ package ggkit
 
 import (
 	"context"
+	"time"
 
 	channel "github.com/dsx137/gg-kit/internal/channel"
 )
 
+type BoundedChannel[T any] = channel.BoundedChannel[T]
+type Broadcaster[T any] = channel.Broadcaster[T]
+type Broker[T any] = channel.Broker[T]
+type Channel[T any] = channel.Channel[T]
+type DrainStats = channel.DrainStats
+type Drainable[T any] = channel.Drainable[T]
+type Future[T any] = channel.Future[T]
+type OverflowPolicy = channel.OverflowPolicy
+type PanicError = channel.PanicError
+type PriorityChannel[T any] = channel.PriorityChannel[T]
+type Request[Req any, Resp any] = channel.Request[Req, Resp]
+type RequestChannel[Req any, Resp any] = channel.RequestChannel[Req, Resp]
+type Selection[T any] = channel.Selection[T]
+type Selector[T any] = channel.Selector[T]
+type Subscription[T any] = channel.Subscription[T]
+
+const OverflowBlock = channel.OverflowBlock
+const OverflowDropNewest = channel.OverflowDropNewest
+const OverflowDropOldest = channel.OverflowDropOldest
+const OverflowError = channel.OverflowError
+const TopicSeparator = channel.TopicSeparator
+const WildcardMany = channel.WildcardMany
+const WildcardOne = channel.WildcardOne
+
+func ErrClosed() error     { return channel.ErrClosed }
+func SetErrClosed(v error) { channel.ErrClosed = v }
+
+func ErrFull() error     { return channel.ErrFull }
+func SetErrFull(v error) { channel.ErrFull = v }
+
+func ErrStop() error     { return channel.ErrStop }
+func SetErrStop(v error) { channel.ErrStop = v }
+
 func Consume[T any](ch <-chan T, handler func(_p0 T) bool) {
 	channel.Consume(ch, handler)
 }
 
+func ConsumeBatch[T any](ctx context.Context, ch <-chan T, maxSize int, maxWait time.Duration, handler func(_p0 []T) bool) {
+	channel.ConsumeBatch(ctx, ch, maxSize, maxWait, handler)
+}
+
+func ConsumeErr[T any](ctx context.Context, ch <-chan T, handler func(_p0 T) error) error {
+	return channel.ConsumeErr(ctx, ch, handler)
+}
+
+func ConsumeParallel[T any](ctx context.Context, ch <-chan T, workers int, handler func(_p0 T) bool) {
+	channel.ConsumeParallel(ctx, ch, workers, handler)
+}
+
 func ConsumeWithCtx[T any](ctx context.Context, ch <-chan T, handler func(_p0 T) bool) {
 	channel.ConsumeWithCtx(ctx, ch, handler)
 }
+
+func Distinct[T comparable](ctx context.Context, in <-chan T) <-chan T {
+	return channel.Distinct(ctx, in)
+}
+
+func Drain[T any](ctx context.Context, ch <-chan T, handler func(_p0 T) bool) DrainStats {
+	return channel.Drain(ctx, ch, handler)
+}
+
+func DrainAndClose[T any](ctx context.Context, ch chan T, handler func(_p0 T) bool) DrainStats {
+	return channel.DrainAndClose(ctx, ch, handler)
+}
+
+func DrainAndCloseQueue[T any](ctx context.Context, q Drainable[T], handler func(_p0 T) bool) DrainStats {
+	return channel.DrainAndCloseQueue(ctx, q, handler)
+}
+
+func DrainQueue[T any](ctx context.Context, q Drainable[T], handler func(_p0 T) bool) DrainStats {
+	return channel.DrainQueue(ctx, q, handler)
+}
+
+func Filter[T any](ctx context.Context, in <-chan T, pred func(_p0 T) bool) <-chan T {
+	return channel.Filter(ctx, in, pred)
+}
+
+func FlatMap[T any, U any](ctx context.Context, in <-chan T, fn func(_p0 T) []U) <-chan U {
+	return channel.FlatMap(ctx, in, fn)
+}
+
+func Map[T any, U any](ctx context.Context, in <-chan T, fn func(_p0 T) U) <-chan U {
+	return channel.Map(ctx, in, fn)
+}
+
+func Merge[T any](ctx context.Context, chans ...<-chan T) <-chan T {
+	return channel.Merge(ctx, chans...)
+}
+
+func NewAgingPriorityChannel[T any](levels int, aging time.Duration) *PriorityChannel[T] {
+	return channel.NewAgingPriorityChannel[T](levels, aging)
+}
+
+func NewBoundedChannel[T any](capacity int, policy OverflowPolicy) *BoundedChannel[T] {
+	return channel.NewBoundedChannel[T](capacity, policy)
+}
+
+func NewBroadcaster[T any](ctx context.Context, in <-chan T) *Broadcaster[T] {
+	return channel.NewBroadcaster(ctx, in)
+}
+
+func NewBroker[T any]() *Broker[T] {
+	return channel.NewBroker[T]()
+}
+
+func NewChannel[T any]() *Channel[T] {
+	return channel.NewChannel[T]()
+}
+
+func NewPriorityChannel[T any](levels int) *PriorityChannel[T] {
+	return channel.NewPriorityChannel[T](levels)
+}
+
+func NewRequestChannel[Req any, Resp any]() *RequestChannel[Req, Resp] {
+	return channel.NewRequestChannel[Req, Resp]()
+}
+
+func NewSelector[T any](chans ...<-chan T) *Selector[T] {
+	return channel.NewSelector(chans...)
+}
+
+func ParallelMap[T any, R any](ctx context.Context, in <-chan T, workers int, fn func(_p0 T) R) <-chan R {
+	return channel.ParallelMap(ctx, in, workers, fn)
+}
+
+func ParallelMapOrdered[T any, R any](ctx context.Context, in <-chan T, workers int, fn func(_p0 T) R) <-chan R {
+	return channel.ParallelMapOrdered(ctx, in, workers, fn)
+}
+
+func Skip[T any](ctx context.Context, in <-chan T, n int) <-chan T {
+	return channel.Skip(ctx, in, n)
+}
+
+func Take[T any](ctx context.Context, in <-chan T, n int) <-chan T {
+	return channel.Take(ctx, in, n)
+}
+
+func Tee[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	return channel.Tee(ctx, in, n)
+}
+
+func Window[T any](ctx context.Context, in <-chan T, size int, step int) <-chan []T {
+	return channel.Window(ctx, in, size, step)
+}
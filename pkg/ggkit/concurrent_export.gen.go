@@ -4,19 +4,70 @@ package ggkit
 
 import (
 	"sync"
+	"time"
 
 	concurrent "github.com/dsx137/gg-kit/internal/concurrent"
 )
 
+type FairKeyedLocker[K comparable] = concurrent.FairKeyedLocker[K]
+type Group[K comparable, V any] = concurrent.Group[K, V]
 type KeyedLocker[K comparable] = concurrent.KeyedLocker[K]
+type KeyedSemaphore[K comparable] = concurrent.KeyedSemaphore[K]
+type LockDiagnostics[K comparable] = concurrent.LockDiagnostics[K]
+type LockEvent[K comparable] = concurrent.LockEvent[K]
+type LockEventKind = concurrent.LockEventKind
+type LockInfo[K comparable] = concurrent.LockInfo[K]
+type LockMode = concurrent.LockMode
+type LockSnapshot[K comparable] = concurrent.LockSnapshot[K]
 type MapKeyedLocker[K comparable] = concurrent.MapKeyedLocker[K]
+type PathLocker = concurrent.PathLocker
+type ReentrantKeyedLocker[K comparable] = concurrent.ReentrantKeyedLocker[K]
 type ReusePool[T any] = concurrent.ReusePool[T]
 type ShardedKeyedLocker[K comparable] = concurrent.ShardedKeyedLocker[K]
+type UpgradableLock[K comparable] = concurrent.UpgradableLock[K]
+
+const LockDeadlock = concurrent.LockDeadlock
+const LockLongHold = concurrent.LockLongHold
+const LockLongWait = concurrent.LockLongWait
+const LockRead = concurrent.LockRead
+const LockWrite = concurrent.LockWrite
+
+func NewDefaultShardedKeyedLocker[K comparable](exp uint) *ShardedKeyedLocker[K] {
+	return concurrent.NewDefaultShardedKeyedLocker[K](exp)
+}
+
+func NewFairKeyedLocker[K comparable]() *FairKeyedLocker[K] {
+	return concurrent.NewFairKeyedLocker[K]()
+}
+
+func NewGroup[K comparable, V any]() *Group[K, V] {
+	return concurrent.NewGroup[K, V]()
+}
+
+func NewHasher[K comparable]() func(_p0 K) uint64 {
+	return concurrent.NewHasher[K]()
+}
+
+func NewKeyedSemaphore[K comparable](size int64) *KeyedSemaphore[K] {
+	return concurrent.NewKeyedSemaphore[K](size)
+}
+
+func NewLockDiagnostics[K comparable](threshold time.Duration, report func(_p0 LockEvent[K])) *LockDiagnostics[K] {
+	return concurrent.NewLockDiagnostics(threshold, report)
+}
 
 func NewMapKeyedLocker[K comparable]() *MapKeyedLocker[K] {
 	return concurrent.NewMapKeyedLocker[K]()
 }
 
+func NewPathLocker() *PathLocker {
+	return concurrent.NewPathLocker()
+}
+
+func NewReentrantKeyedLocker[K comparable]() *ReentrantKeyedLocker[K] {
+	return concurrent.NewReentrantKeyedLocker[K]()
+}
+
 func NewReusePool[T any](factory func() (*T, error), validator func(_p0 *T) bool, closer func(_p0 *T) error) (*ReusePool[T], error) {
 	return concurrent.NewReusePool(factory, validator, closer)
 }
@@ -25,6 +76,10 @@ func NewShardedKeyedLocker[K comparable](exp uint, hash func(_p0 K) uint64) *Sha
 	return concurrent.NewShardedKeyedLocker(exp, hash)
 }
 
+func NewWriterPreferringKeyedLocker[K comparable]() *FairKeyedLocker[K] {
+	return concurrent.NewWriterPreferringKeyedLocker[K]()
+}
+
 func WithLock(locker sync.Locker, f func()) {
 	concurrent.WithLock(locker, f)
 }